@@ -19,7 +19,7 @@ func middlewareForV2() tinyGin.HandlerFunc {
 		// if a server error occurred
 		c.Fail(500, "Internal Server Error")
 		// Calculate resolution time
-		log.Printf("[%d] %s in %v", c.StatusCode, c.Req.RequestURI, time.Since(t))
+		log.Printf("[%d] %s in %v", c.Writer.Status(), c.Req.RequestURI, time.Since(t))
 	}
 }
 
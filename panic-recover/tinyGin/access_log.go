@@ -0,0 +1,72 @@
+package tinyGin
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/*
+Apache/NCSA 格式的访问日志，供只认识这些格式的旧日志工具使用，例如
+	r.Use(LoggerWithConfig(LoggerConfig{Formatter: CombinedLogFormatter, Output: w}))
+Common Log Format:   %h %l %u %t "%r" %>s %b
+Combined Log Format: %h %l %u %t "%r" %>s %b "%{Referer}i" "%{User-agent}i"
+*/
+
+// clfTimeFormat CLF 中的时间格式，例如 10/Oct/2000:13:55:36 -0700
+const clfTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+// CommonLogFormatter 输出 Common Log Format 格式的日志
+func CommonLogFormatter(param LogFormatterParams) string {
+	return commonLog(param) + "\n"
+}
+
+// CombinedLogFormatter 在 Common Log Format 的基础上增加了 Referer 和 User-Agent
+func CombinedLogFormatter(param LogFormatterParams) string {
+	referer, userAgent := "", ""
+	if param.Request != nil {
+		referer = param.Request.Referer()
+		userAgent = param.Request.UserAgent()
+	}
+	return fmt.Sprintf("%s \"%s\" \"%s\"\n", commonLog(param), clfEscape(referer), clfEscape(userAgent))
+}
+
+func commonLog(param LogFormatterParams) string {
+	user, requestLine := "-", "-"
+	if req := param.Request; req != nil {
+		if username, _, ok := req.BasicAuth(); ok && username != "" {
+			user = clfEscape(username)
+		}
+		requestLine = clfEscape(maskRequestLine(fmt.Sprintf("%s %s %s", req.Method, req.RequestURI, req.Proto)))
+	}
+	size := "-"
+	if param.BodySize > 0 {
+		size = strconv.Itoa(param.BodySize)
+	}
+	host := param.ClientIP
+	if host == "" {
+		host = "-"
+	}
+	// %t 是收到请求的时间，TimeStamp 是处理完成的时间
+	received := param.TimeStamp.Add(-param.Latency)
+	return fmt.Sprintf("%s - %s [%s] \"%s\" %d %s",
+		host, user, received.Format(clfTimeFormat), requestLine, param.StatusCode, size)
+}
+
+// clfEscape 转义引号、反斜杠和控制字符，防止伪造日志行
+func clfEscape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case ch == '"' || ch == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(ch)
+		case ch < 0x20 || ch == 0x7f:
+			fmt.Fprintf(&b, "\\x%02x", ch)
+		default:
+			b.WriteByte(ch)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,31 @@
+package tinyGin
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCombinedLogFormatter(t *testing.T) {
+	req := httptest.NewRequest("GET", "/apache_pb.gif?x=1&access_token=abc", nil)
+	req.SetBasicAuth("frank", "secret")
+	req.Header.Set("Referer", "http://www.example.com/start.html")
+	req.Header.Set("User-Agent", `Mozilla/4.08 "evil"`)
+	end := time.Date(2000, 10, 10, 13, 55, 37, 0, time.FixedZone("", -7*3600))
+	param := LogFormatterParams{
+		Request:    req,
+		TimeStamp:  end,
+		Latency:    time.Second,
+		ClientIP:   "127.0.0.1",
+		StatusCode: 200,
+		BodySize:   2326,
+	}
+	want := `127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif?x=1&access_token=* HTTP/1.1" 200 2326`
+	if got := CommonLogFormatter(param); got != want+"\n" {
+		t.Fatalf("common:\n got %s\nwant %s", got, want)
+	}
+	want += ` "http://www.example.com/start.html" "Mozilla/4.08 \"evil\""` + "\n"
+	if got := CombinedLogFormatter(param); got != want {
+		t.Fatalf("combined:\n got %s\nwant %s", got, want)
+	}
+}
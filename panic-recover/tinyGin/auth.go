@@ -0,0 +1,156 @@
+package tinyGin
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+/*
+鉴权中间件。
+BasicAuth、BearerAuth 和 APIKeyAuth 校验通过后把当前用户保存在 c.Keys[AuthUserKey] 中，
+handler 中通过 c.MustGet(AuthUserKey) 获取；校验失败时返回 401 并通过 WWW-Authenticate 告诉客户端需要的认证方式，
+不再执行后续的 handler。
+*/
+
+// AuthUserKey 鉴权通过后当前用户在 Context 中保存的 key
+const AuthUserKey = "user"
+
+// defaultAPIKeyHeader APIKeyConfig 没有指定任何来源时读取的请求头
+const defaultAPIKeyHeader = "X-API-Key"
+
+// Accounts BasicAuth 的用户名和密码
+type Accounts map[string]string
+
+// basicCredential 预先计算好的 Authorization 请求头
+type basicCredential struct {
+	user  string
+	value []byte
+}
+
+// BasicAuth HTTP Basic 认证中间件，realm 为 "Authorization Required"
+func BasicAuth(accounts Accounts) HandlerFunc {
+	return BasicAuthForRealm(accounts, "")
+}
+
+// BasicAuthForRealm HTTP Basic 认证中间件，用户名保存在 c.Keys[AuthUserKey] 中
+func BasicAuthForRealm(accounts Accounts, realm string) HandlerFunc {
+	if realm == "" {
+		realm = "Authorization Required"
+	}
+	challenge := "Basic realm=" + strconv.Quote(realm) + `, charset="UTF-8"`
+	credentials := make([]basicCredential, 0, len(accounts))
+	for user, password := range accounts {
+		if user == "" {
+			panic("tinyGin: BasicAuth user can not be empty")
+		}
+		value := "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+password))
+		credentials = append(credentials, basicCredential{user: user, value: []byte(value)})
+	}
+	return func(c *Context) {
+		header := []byte(c.Req.Header.Get("Authorization"))
+		user, found := "", false
+		// 与所有账户逐一比较，不提前返回，比较的时间与哪个账户匹配无关
+		for _, cred := range credentials {
+			if subtle.ConstantTimeCompare(header, cred.value) == 1 {
+				user, found = cred.user, true
+			}
+		}
+		if !found {
+			c.SetHeader("WWW-Authenticate", challenge)
+			c.Fail(http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+		c.Set(AuthUserKey, user)
+		c.Next()
+	}
+}
+
+// BearerAuth RFC 6750 Bearer Token 认证中间件
+// validate 校验令牌并返回对应的用户，用户保存在 c.Keys[AuthUserKey] 中；返回错误时请求被拒绝，错误记录在 c.Errors 中
+func BearerAuth(validate func(token string) (interface{}, error)) HandlerFunc {
+	return func(c *Context) {
+		scheme, token, _ := strings.Cut(c.Req.Header.Get("Authorization"), " ")
+		token = strings.TrimSpace(token)
+		if !strings.EqualFold(scheme, "Bearer") || token == "" {
+			c.SetHeader("WWW-Authenticate", "Bearer")
+			c.Fail(http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+		user, err := validate(token)
+		if err != nil {
+			c.Error(err)
+			c.SetHeader("WWW-Authenticate", `Bearer error="invalid_token"`)
+			c.Fail(http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+		c.Set(AuthUserKey, user)
+		c.Next()
+	}
+}
+
+// APIKeyConfig APIKeyAuth 的配置
+// Header、Query、Cookie 按顺序读取，使用第一个不为空的值，都没有指定时从 X-API-Key 请求头读取
+type APIKeyConfig struct {
+	// Header 保存 API Key 的请求头
+	Header string
+	// Query 保存 API Key 的 Query 参数，API Key 会出现在访问日志和浏览器历史中，尽量使用请求头
+	Query string
+	// Cookie 保存 API Key 的 Cookie
+	Cookie string
+	// Validate 校验 API Key 并返回对应的用户，比较 Key 时应当使用 crypto/subtle.ConstantTimeCompare
+	Validate func(key string) (interface{}, error)
+}
+
+// APIKeyAuth API Key 认证中间件，用户保存在 c.Keys[AuthUserKey] 中
+func APIKeyAuth(conf APIKeyConfig) HandlerFunc {
+	if conf.Validate == nil {
+		panic("tinyGin: APIKeyAuth requires a Validate function")
+	}
+	if conf.Header == "" && conf.Query == "" && conf.Cookie == "" {
+		conf.Header = defaultAPIKeyHeader
+	}
+	challenge := "APIKey"
+	if conf.Header != "" {
+		challenge += " header=" + strconv.Quote(conf.Header)
+	}
+	return func(c *Context) {
+		key := conf.lookup(c)
+		if key == "" {
+			c.SetHeader("WWW-Authenticate", challenge)
+			c.Fail(http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+		user, err := conf.Validate(key)
+		if err != nil {
+			c.Error(err)
+			c.SetHeader("WWW-Authenticate", challenge)
+			c.Fail(http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+		c.Set(AuthUserKey, user)
+		c.Next()
+	}
+}
+
+// lookup 按照 Header、Query、Cookie 的顺序读取 API Key
+func (conf *APIKeyConfig) lookup(c *Context) string {
+	if conf.Header != "" {
+		if key := c.Req.Header.Get(conf.Header); key != "" {
+			return key
+		}
+	}
+	if conf.Query != "" {
+		if key := c.Query(conf.Query); key != "" {
+			return key
+		}
+	}
+	if conf.Cookie != "" {
+		if key, err := c.Cookie(conf.Cookie); err == nil {
+			return key
+		}
+	}
+	return ""
+}
@@ -0,0 +1,116 @@
+package tinyGin
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// authServer 注册使用 mw 鉴权的路由，返回当前用户
+func authServer(mw HandlerFunc) *Engine {
+	r := New()
+	r.Use(mw)
+	r.GET("/me", func(c *Context) {
+		c.String(http.StatusOK, "%v", c.MustGet(AuthUserKey))
+	})
+	return r
+}
+
+func TestBasicAuth(t *testing.T) {
+	r := authServer(BasicAuthForRealm(Accounts{"amadeus": "secret", "kurisu": "el psy"}, "admin"))
+	cases := []struct {
+		user, password string
+		status         int
+		body           string
+	}{
+		{"amadeus", "secret", http.StatusOK, "amadeus"},
+		{"kurisu", "el psy", http.StatusOK, "kurisu"},
+		{"amadeus", "wrong", http.StatusUnauthorized, ""},
+		{"", "", http.StatusUnauthorized, ""},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest("GET", "/me", nil)
+		if tc.user != "" {
+			req.SetBasicAuth(tc.user, tc.password)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tc.status || (tc.body != "" && w.Body.String() != tc.body) {
+			t.Errorf("%s: got %d %q", tc.user, w.Code, w.Body.String())
+		}
+		if tc.status == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") != `Basic realm="admin", charset="UTF-8"` {
+			t.Errorf("%s: unexpected WWW-Authenticate %q", tc.user, w.Header().Get("WWW-Authenticate"))
+		}
+	}
+}
+
+func TestBearerAuth(t *testing.T) {
+	r := authServer(BearerAuth(func(token string) (interface{}, error) {
+		if token != "valid" {
+			return nil, errors.New("unknown token")
+		}
+		return "amadeus", nil
+	}))
+	cases := []struct {
+		header    string
+		status    int
+		challenge string
+	}{
+		{"Bearer valid", http.StatusOK, ""},
+		{"bearer valid", http.StatusOK, ""},
+		{"", http.StatusUnauthorized, "Bearer"},
+		{"Basic dXNlcjpwYXNz", http.StatusUnauthorized, "Bearer"},
+		{"Bearer expired", http.StatusUnauthorized, `Bearer error="invalid_token"`},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest("GET", "/me", nil)
+		req.Header.Set("Authorization", tc.header)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tc.status || w.Header().Get("WWW-Authenticate") != tc.challenge {
+			t.Errorf("%q: got %d %q", tc.header, w.Code, w.Header().Get("WWW-Authenticate"))
+		}
+	}
+}
+
+func TestAPIKeyAuth(t *testing.T) {
+	r := authServer(APIKeyAuth(APIKeyConfig{
+		Header: "X-API-Key",
+		Query:  "api_key",
+		Cookie: "api_key",
+		Validate: func(key string) (interface{}, error) {
+			if key != "k1" {
+				return nil, errors.New("unknown key")
+			}
+			return "service-a", nil
+		},
+	}))
+	header := httptest.NewRequest("GET", "/me", nil)
+	header.Header.Set("X-API-Key", "k1")
+	query := httptest.NewRequest("GET", "/me?api_key=k1", nil)
+	cookie := httptest.NewRequest("GET", "/me", nil)
+	cookie.AddCookie(&http.Cookie{Name: "api_key", Value: "k1"})
+	invalid := httptest.NewRequest("GET", "/me?api_key=k2", nil)
+	missing := httptest.NewRequest("GET", "/me", nil)
+
+	for i, tc := range []struct {
+		req    *http.Request
+		status int
+	}{
+		{header, http.StatusOK}, {query, http.StatusOK}, {cookie, http.StatusOK},
+		{invalid, http.StatusUnauthorized}, {missing, http.StatusUnauthorized},
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, tc.req)
+		if w.Code != tc.status {
+			t.Errorf("case %d: got %d", i, w.Code)
+		}
+		if tc.status == http.StatusOK && w.Body.String() != "service-a" {
+			t.Errorf("case %d: unexpected user %q", i, w.Body.String())
+		}
+		if tc.status == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") != `APIKey header="X-API-Key"` {
+			t.Errorf("case %d: unexpected WWW-Authenticate %q", i, w.Header().Get("WWW-Authenticate"))
+		}
+	}
+}
@@ -0,0 +1,330 @@
+package tinyGin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// 常用的 Content-Type
+const (
+	MIMEJSON              = "application/json"
+	MIMEHTML              = "text/html"
+	MIMEPlain             = "text/plain"
+	MIMEXML               = "application/xml"
+	MIMEXML2              = "text/xml"
+	MIMEYAML              = "application/yaml"
+	MIMEYAML2             = "application/x-yaml"
+	MIMEPOSTForm          = "application/x-www-form-urlencoded"
+	MIMEMultipartPOSTForm = "multipart/form-data"
+)
+
+// Binding 将请求中的数据解析到结构体中
+// 不同的数据来源(JSON、表单、Query)各自实现这个接口，解析完成后统一交给 validate 做参数校验
+type Binding interface {
+	Name() string
+	Bind(req *http.Request, obj interface{}) error
+}
+
+// BindingBody 可以直接从已经读取的请求体中解析数据的 Binding，供 ShouldBindBodyWith 使用
+type BindingBody interface {
+	Binding
+	BindBody(body []byte, obj interface{}) error
+}
+
+// 内置的 Binding 实现
+var (
+	JSON  BindingBody = jsonBinding{}
+	Form  BindingBody = formBinding{}
+	Query Binding     = queryBinding{}
+)
+
+type jsonBinding struct{}
+
+func (jsonBinding) Name() string { return "json" }
+
+func (jsonBinding) Bind(req *http.Request, obj interface{}) error {
+	if req == nil || req.Body == nil {
+		return errors.New("invalid request")
+	}
+	return json.NewDecoder(req.Body).Decode(obj)
+}
+
+func (jsonBinding) BindBody(body []byte, obj interface{}) error {
+	return json.Unmarshal(body, obj)
+}
+
+type formBinding struct{}
+
+func (formBinding) Name() string { return "form" }
+
+func (formBinding) Bind(req *http.Request, obj interface{}) error {
+	if err := req.ParseForm(); err != nil {
+		return err
+	}
+	// multipart 表单需要单独解析，普通表单在这里会返回 ErrNotMultipart，忽略即可
+	// 通过 Context 绑定时表单已经按照 Engine.MaxMultipartMemory 解析过，这里不会重复解析
+	if err := req.ParseMultipartForm(defaultMultipartMemory); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		return err
+	}
+	return mapForm(obj, req.Form)
+}
+
+// BindBody 只解析 application/x-www-form-urlencoded 格式的请求体
+func (formBinding) BindBody(body []byte, obj interface{}) error {
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return err
+	}
+	return mapForm(obj, values)
+}
+
+type queryBinding struct{}
+
+func (queryBinding) Name() string { return "query" }
+
+func (queryBinding) Bind(req *http.Request, obj interface{}) error {
+	return mapForm(obj, req.URL.Query())
+}
+
+// defaultBinding 根据请求方法和 Content-Type 选择对应的 Binding
+func defaultBinding(method, contentType string) Binding {
+	if method == http.MethodGet {
+		return Form
+	}
+	switch contentType {
+	case MIMEJSON:
+		return JSON
+	default:
+		return Form
+	}
+}
+
+// mapForm 按照 form tag 将 url.Values 中的值写入结构体字段，没有 tag 时使用字段名
+func mapForm(obj interface{}, values url.Values) error {
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("binding: obj must be a non-nil pointer")
+	}
+	rv = rv.Elem()
+	if rv.Kind() != reflect.Struct {
+		return errors.New("binding: obj must point to a struct")
+	}
+	return mapFormStruct(rv, values)
+}
+
+func mapFormStruct(rv reflect.Value, values url.Values) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.PkgPath != "" && !field.Anonymous { // 未导出的字段
+			continue
+		}
+		name := field.Tag.Get("form")
+		if name == "-" {
+			continue
+		}
+		fv := rv.Field(i)
+		// 嵌套的结构体(包括匿名字段)继续用同一份 values 解析
+		if name == "" && fv.Kind() == reflect.Struct && field.Type != timeType {
+			if err := mapFormStruct(fv, values); err != nil {
+				return err
+			}
+			continue
+		}
+		// 未导出的匿名字段只有是结构体时才能通过它设置导出的字段，其他情况无法赋值
+		if !fv.CanSet() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		vs, ok := values[name]
+		if !ok || len(vs) == 0 {
+			continue
+		}
+		if err := setField(fv, vs); err != nil {
+			return fmt.Errorf("binding: field %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func setField(fv reflect.Value, vs []string) error {
+	switch fv.Kind() {
+	case reflect.Ptr:
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+		return setField(fv.Elem(), vs)
+	case reflect.Slice:
+		slice := reflect.MakeSlice(fv.Type(), len(vs), len(vs))
+		for i, v := range vs {
+			if err := setValue(slice.Index(i), v); err != nil {
+				return err
+			}
+		}
+		fv.Set(slice)
+		return nil
+	case reflect.Array:
+		if len(vs) != fv.Len() {
+			return fmt.Errorf("expected %d values, got %d", fv.Len(), len(vs))
+		}
+		for i, v := range vs {
+			if err := setValue(fv.Index(i), v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return setValue(fv, vs[0])
+}
+
+func setValue(fv reflect.Value, v string) error {
+	if !fv.CanSet() {
+		return nil
+	}
+	if fv.Type() == timeType {
+		if v == "" {
+			return nil
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(t))
+		return nil
+	}
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(v)
+	case reflect.Bool:
+		if v == "" {
+			v = "false"
+		}
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v == "" {
+			v = "0"
+		}
+		n, err := strconv.ParseInt(v, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v == "" {
+			v = "0"
+		}
+		n, err := strconv.ParseUint(v, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		if v == "" {
+			v = "0"
+		}
+		f, err := strconv.ParseFloat(v, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(f)
+	case reflect.Ptr:
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+		return setValue(fv.Elem(), v)
+	default:
+		return fmt.Errorf("unsupported type %s", fv.Type())
+	}
+	return nil
+}
+
+// ContentType 返回请求的 Content-Type，不包含 charset 等参数
+func (c *Context) ContentType() string {
+	ct := c.Req.Header.Get("Content-Type")
+	if ct == "" {
+		return ""
+	}
+	mediaType, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return ct
+	}
+	return mediaType
+}
+
+// ShouldBind 根据请求方法和 Content-Type 自动选择 Binding，解析并校验参数
+// 返回的错误交给调用方处理，校验失败时错误类型为 ValidationErrors
+func (c *Context) ShouldBind(obj interface{}) error {
+	return c.ShouldBindWith(obj, defaultBinding(c.Method, c.ContentType()))
+}
+
+// ShouldBindJSON 按 JSON 解析请求体
+func (c *Context) ShouldBindJSON(obj interface{}) error {
+	return c.ShouldBindWith(obj, JSON)
+}
+
+// ShouldBindQuery 只解析 URL 中的 Query 参数
+func (c *Context) ShouldBindQuery(obj interface{}) error {
+	return c.ShouldBindWith(obj, Query)
+}
+
+// ShouldBindWith 使用指定的 Binding 解析参数，解析成功后再按 binding tag 校验
+func (c *Context) ShouldBindWith(obj interface{}, b Binding) error {
+	// Binding 只能拿到 *http.Request，multipart 表单先按照 Engine.MaxMultipartMemory 解析
+	if _, ok := b.(formBinding); ok && c.ContentType() == MIMEMultipartPOSTForm {
+		if _, err := c.MultipartForm(); err != nil {
+			return err
+		}
+	}
+	if err := b.Bind(c.Req, obj); err != nil {
+		return err
+	}
+	return validate(obj)
+}
+
+// Bind 与 ShouldBind 相同，但失败时直接中断后续处理，返回 400 以及每个校验失败的字段
+func (c *Context) Bind(obj interface{}) error {
+	err := c.ShouldBind(obj)
+	if err != nil {
+		c.Error(err).SetType(ErrorTypeBind)
+		c.AbortWithBindError(err)
+	}
+	return err
+}
+
+// AbortWithBindError 将参数解析或校验错误以 400 JSON 的形式返回
+// Engine.ProblemDetails 为 true 时返回 problem+json，校验失败的字段放在扩展字段 errors 中
+func (c *Context) AbortWithBindError(err error) {
+	var verrs ValidationErrors
+	if c.useProblemDetails() {
+		p := NewProblem(http.StatusBadRequest, err.Error())
+		if errors.As(err, &verrs) {
+			p.Detail = "validation failed"
+			p.Extensions = map[string]interface{}{"errors": verrs}
+		}
+		c.AbortWithProblem(p)
+		return
+	}
+	if errors.As(err, &verrs) {
+		c.AbortWithStatusJson(http.StatusBadRequest, H{
+			"message": "validation failed",
+			"errors":  verrs,
+		})
+		return
+	}
+	c.AbortWithStatusJson(http.StatusBadRequest, H{"message": err.Error()})
+}
@@ -0,0 +1,100 @@
+package tinyGin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type signupForm struct {
+	Name  string   `json:"name" form:"name" binding:"required,min=3,max=8"`
+	Email string   `json:"email" form:"email" binding:"omitempty,email"`
+	Role  string   `json:"role" form:"role" binding:"oneof=admin user"`
+	Tags  []string `json:"tags" form:"tag" binding:"max=2"`
+	Age   int      `json:"age" form:"age" binding:"min=18"`
+}
+
+func TestShouldBindForm(t *testing.T) {
+	req := httptest.NewRequest("GET", "/?name=amadeus&role=user&tag=a&tag=b&age=20", nil)
+	c := newContext(httptest.NewRecorder(), req)
+	var form signupForm
+	if err := c.ShouldBind(&form); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if form.Name != "amadeus" || form.Age != 20 || len(form.Tags) != 2 {
+		t.Fatalf("unexpected form: %+v", form)
+	}
+}
+
+type auditInfo struct {
+	Source string `form:"source" binding:"oneof=web app"`
+}
+
+type level int
+
+// unexportedForm 包含未导出的字段和未导出的匿名字段，绑定时应当跳过而不是 panic
+type unexportedForm struct {
+	auditInfo
+	*signupForm
+	level `binding:"oneof=1 2"`
+	Name  string `form:"name"`
+	token string `form:"token" binding:"oneof=a b"`
+}
+
+func TestShouldBindUnexportedFields(t *testing.T) {
+	req := httptest.NewRequest("GET", "/?name=amadeus&source=web&token=secret&level=3", nil)
+	c := newContext(httptest.NewRecorder(), req)
+	var form unexportedForm
+	if err := c.ShouldBind(&form); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if form.Name != "amadeus" || form.Source != "web" || form.token != "" || form.level != 0 || form.signupForm != nil {
+		t.Fatalf("unexpected form: %+v", form)
+	}
+}
+
+func TestShouldBindValidation(t *testing.T) {
+	body := `{"name":"am","email":"not-an-email","role":"root","tags":["a","b","c"],"age":3}`
+	req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	c := newContext(httptest.NewRecorder(), req)
+	var form signupForm
+	err := c.ShouldBind(&form)
+	var verrs ValidationErrors
+	if !errors.As(err, &verrs) {
+		t.Fatalf("expected ValidationErrors, got %v", err)
+	}
+	got := make([]string, len(verrs))
+	for i, fe := range verrs {
+		got[i] = fe.Field + ":" + fe.Tag
+	}
+	want := "name:min,email:email,role:oneof,tags:max,age:min"
+	if strings.Join(got, ",") != want {
+		t.Fatalf("got %v, want %s", got, want)
+	}
+}
+
+func TestBindAbortsWith400(t *testing.T) {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/?role=user&age=20", nil)
+	c := newContext(w, req)
+	var form signupForm
+	if err := c.Bind(&form); err == nil {
+		t.Fatal("expected error")
+	}
+	if !c.IsAborted() || w.Code != http.StatusBadRequest {
+		t.Fatalf("expected aborted 400, got %d", w.Code)
+	}
+	var resp struct {
+		Errors []FieldError `json:"errors"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Errors) != 1 || resp.Errors[0].Field != "name" || resp.Errors[0].Tag != "required" {
+		t.Fatalf("unexpected errors: %+v", resp.Errors)
+	}
+}
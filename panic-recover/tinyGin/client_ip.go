@@ -0,0 +1,141 @@
+package tinyGin
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+/*
+获取客户端的真实 IP。
+服务部署在反向代理之后时，RemoteAddr 是代理的地址，真实的客户端地址由代理写入 X-Forwarded-For、X-Real-IP 或者 Forwarded(RFC 7239)。
+这些请求头客户端也可以随意伪造，所以只有当请求来自 Engine.SetTrustedProxies 配置的可信代理时才会使用，
+X-Forwarded-For 和 Forwarded 中可能有多层代理，从右往左跳过可信代理，第一个不可信的地址就是客户端地址。
+*/
+
+// defaultRemoteIPHeaders Engine.RemoteIPHeaders 的默认值
+var defaultRemoteIPHeaders = []string{"Forwarded", "X-Forwarded-For", "X-Real-IP"}
+
+// SetTrustedProxies 设置可信代理，可以是单个 IP 或者 CIDR，例如 10.0.0.1、192.168.0.0/16、fd00::/8
+// 传入 nil 时不信任任何代理，ClientIP 总是返回 RemoteIP
+func (e *Engine) SetTrustedProxies(proxies []string) error {
+	cidrs := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return fmt.Errorf("tinyGin: invalid trusted proxy %q", proxy)
+			}
+			if ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+		_, cidr, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return err
+		}
+		cidrs = append(cidrs, cidr)
+	}
+	e.trustedCIDRs = cidrs
+	return nil
+}
+
+// isTrustedProxy 判断 ip 是否属于可信代理
+func (e *Engine) isTrustedProxy(ip net.IP) bool {
+	for _, cidr := range e.trustedCIDRs {
+		if cidr.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoteIP 返回直接与服务端建立连接的地址，不考虑任何代理请求头
+func (c *Context) RemoteIP() string {
+	ip, _, err := net.SplitHostPort(strings.TrimSpace(c.Req.RemoteAddr))
+	if err != nil {
+		return strings.TrimSpace(c.Req.RemoteAddr)
+	}
+	return ip
+}
+
+// ClientIP 返回客户端的真实 IP
+// 请求来自可信代理时，按 Engine.RemoteIPHeaders 的顺序从请求头中解析，否则返回 RemoteIP
+func (c *Context) ClientIP() string {
+	remoteIP := net.ParseIP(c.RemoteIP())
+	if remoteIP == nil {
+		return ""
+	}
+	if c.engine == nil || !c.engine.isTrustedProxy(remoteIP) {
+		return remoteIP.String()
+	}
+	for _, header := range c.engine.RemoteIPHeaders {
+		values := c.Req.Header.Values(header)
+		if len(values) == 0 {
+			continue
+		}
+		var chain []string
+		switch strings.ToLower(header) {
+		case "forwarded":
+			chain = parseForwarded(strings.Join(values, ","))
+		case "x-real-ip":
+			chain = []string{strings.TrimSpace(values[0])}
+		default:
+			chain = strings.Split(strings.Join(values, ","), ",")
+		}
+		if ip, ok := c.engine.clientIPFromChain(chain); ok {
+			return ip
+		}
+	}
+	return remoteIP.String()
+}
+
+// clientIPFromChain 从右往左跳过可信代理，返回第一个不可信的地址
+// 链中有不合法的地址时说明请求头不可信，返回 false
+func (e *Engine) clientIPFromChain(chain []string) (string, bool) {
+	for i := len(chain) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(chain[i]))
+		if ip == nil {
+			return "", false
+		}
+		if i == 0 || !e.isTrustedProxy(ip) {
+			return ip.String(), true
+		}
+	}
+	return "", false
+}
+
+// parseForwarded 解析 RFC 7239 的 Forwarded 请求头，按顺序返回每一跳的 for 参数
+// 例如 for=192.0.2.60;proto=http, for="[2001:db8::1]:4711" 返回 [192.0.2.60 2001:db8::1]
+// 没有 for 参数或者是 unknown、_hidden 这类混淆标识符的一跳返回空字符串，在 clientIPFromChain 中视为不合法
+func parseForwarded(header string) []string {
+	var chain []string
+	for _, element := range strings.Split(header, ",") {
+		node := ""
+		for _, pair := range strings.Split(element, ";") {
+			key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok || !strings.EqualFold(strings.TrimSpace(key), "for") {
+				continue
+			}
+			node = strings.Trim(strings.TrimSpace(value), `"`)
+		}
+		chain = append(chain, forwardedNodeIP(node))
+	}
+	return chain
+}
+
+// forwardedNodeIP 去掉 Forwarded 中节点的端口和 IPv6 的方括号
+func forwardedNodeIP(node string) string {
+	if strings.HasPrefix(node, "[") {
+		if end := strings.IndexByte(node, ']'); end > 0 {
+			return node[1:end]
+		}
+		return ""
+	}
+	if host, _, err := net.SplitHostPort(node); err == nil {
+		return host
+	}
+	return node
+}
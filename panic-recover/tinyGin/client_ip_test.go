@@ -0,0 +1,39 @@
+package tinyGin
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	e := New()
+	if err := e.SetTrustedProxies([]string{"10.0.0.0/8", "2001:db8::1"}); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{"untrusted remote ignores headers", "203.0.113.9:1234", map[string]string{"X-Forwarded-For": "1.1.1.1"}, "203.0.113.9"},
+		{"skips trusted hops", "10.0.0.1:80", map[string]string{"X-Forwarded-For": "6.6.6.6, 198.51.100.7, 10.1.1.1"}, "198.51.100.7"},
+		{"all hops trusted", "10.0.0.1:80", map[string]string{"X-Forwarded-For": "10.2.2.2, 10.1.1.1"}, "10.2.2.2"},
+		{"invalid xff falls back", "10.0.0.1:80", map[string]string{"X-Forwarded-For": "bogus", "X-Real-IP": "198.51.100.8"}, "198.51.100.8"},
+		{"forwarded header", "[2001:db8::1]:443", map[string]string{"Forwarded": `for=192.0.2.60;proto=http, for="[2001:db8::2]:4711";by=x`}, "2001:db8::2"},
+		{"forwarded obfuscated", "10.0.0.1:80", map[string]string{"Forwarded": "for=_hidden", "X-Real-IP": "198.51.100.9"}, "198.51.100.9"},
+		{"no headers", "10.0.0.1:80", nil, "10.0.0.1"},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = tc.remoteAddr
+		for k, v := range tc.headers {
+			req.Header.Set(k, v)
+		}
+		c := newContext(httptest.NewRecorder(), req)
+		c.engine = e
+		if got := c.ClientIP(); got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
@@ -1,22 +1,48 @@
 package tinyGin
 
 import (
-	"encoding/json"
-	"fmt"
+	"encoding/xml"
+	"errors"
+	"math"
 	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"tinyGin/render"
 )
 
 /*
 Context的结构体，结构体中包含三类元素。
 首先是origin object（http.ResponseWriter、*http.Request），在之前我们已经知道这是一个route的处理函数所必须的输入参数；
 然后是跟请求有关的信息request info，Path和Method都是从http.ResponseWriter取出的信息；
-最后是跟响应有关的信息response info,StatusCode即响应码
+最后是跟响应有关的信息response info，状态码和响应大小由 Writer 记录，通过 c.Writer.Status()、c.Writer.Size() 获取
 */
 
 // H
 // 给map[string]interface{}起了一个别名tinyGin.H，构建JSON数据时，显得更简洁
 type H map[string]interface{}
 
+// MarshalXML 让 H 也可以直接用于 XML 响应，序列化成 <map><key>value</key></map> 的形式
+func (h H) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name = xml.Name{Local: "map"}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(h))
+	for key := range h {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		elem := xml.StartElement{Name: xml.Name{Local: key}}
+		if err := e.EncodeElement(h[key], elem); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(xml.EndElement{Name: start.Name})
+}
+
 // Context
 // 要构造一个完整的响应，需要考虑消息头(Header)和消息体(Body)
 // 而 Header 包含了状态码(StatusCode)，消息类型(ContentType)等几乎每次请求都需要设置的信息，需要进行有效的封装
@@ -24,8 +50,9 @@ type H map[string]interface{}
 // Context 随着每一个请求的出现而产生，请求的结束而销毁，和当前请求强相关的信息都应由 Context 承载
 type Context struct {
 	// origin objects
-	Writer http.ResponseWriter
-	Req    *http.Request
+	writermem responseWriter
+	Writer    ResponseWriter // 包装了原始的 http.ResponseWriter，记录状态码和响应大小
+	Req       *http.Request
 	// request info
 	Path   string
 	Method string
@@ -34,92 +61,375 @@ type Context struct {
 	// 将解析后的参数存储到Params中，通过c.Param("lang")的方式获取到对应的值
 	Params map[string]string
 	// response info
+	// Deprecated: StatusCode 只是 c.Writer.Status() 的副本，通过 c.Status 设置状态码和请求处理结束时同步，
+	// 直接调用 c.Writer.WriteHeader 时不会立即更新，请使用 c.Writer.Status()
 	StatusCode int
+	// fullPath 匹配到的路由，例如 /user/:name
+	fullPath string
 	// middleware
-	handlers []HandlerFunc // 所有需要实现的handler方法
-	index    int           // 当前执行的位置，即记录当前执行到第几个中间件
+	handlers []HandlerFunc  // 所有需要实现的handler方法
+	index    int            // 当前执行的位置，即记录当前执行到第几个中间件
+	forwards int            // 已经通过 Forward 内部转发的次数
+	groups   []*RouterGroup // 已经加入处理链的分组，Forward 时不再重复执行这些分组的中间件
+	// Errors 处理请求过程中通过 c.Error 记录的错误
+	Errors errorMsgs
+	// 缓存解析后的 Query 参数和表单参数
+	queryCache url.Values
+	formCache  url.Values
+	// 缓存的请求体，由 GetRawData 读取
+	rawData []byte
+	// Keys 在中间件和 handler 之间传递数据，通过 c.Set/c.Get 访问
+	Keys map[string]interface{}
+	mu   sync.RWMutex // 保护 Keys
+	// timeout 最外层 Timeout 中间件创建的超时控制，内层的 Timeout 通过它修改截止时间
+	timeout *timeoutController
 	// engine pointer
 	engine *Engine // 能够通过 Context 访问 Engine 中的 HTML 模板
 }
 
 func newContext(w http.ResponseWriter, req *http.Request) *Context {
-	return &Context{
-		Writer: w,
+	c := &Context{
 		Req:    req,
 		Path:   req.URL.Path,
 		Method: req.Method,
 		index:  -1,
 	}
+	c.writermem.reset(w)
+	c.Writer = &c.writermem
+	c.StatusCode = c.Writer.Status()
+	return c
 }
 
 // Next 当在中间件中调用Next方法时，控制权交给了下一个中间件，直到调用到最后一个中间件，然后再从后往前，调用每个中间件在Next方法之后定义的部分
 // 因为有一类中间件需要处理流程开始之前执行，在处理流程结束之后才结束，比如实现一个记录处理时间的中间件
 func (c *Context) Next() {
 	c.index++
-	s := len(c.handlers)
-	for ; c.index < s; c.index++ {
+	for ; c.index < len(c.handlers); c.index++ {
 		c.handlers[c.index](c)
 	}
 }
 
+// abortIndex 中断后 index 被设置成的值，大于任何可能的 handlers 长度
+const abortIndex int = math.MaxInt32
+
+// Abort 阻止调用后续的 handler，但不会中断当前 handler 的执行
+// 例如鉴权中间件校验失败时调用 Abort，之后的中间件和路由处理函数都不会再执行
+func (c *Context) Abort() {
+	c.index = abortIndex
+}
+
+// IsAborted 当前请求是否已经被中断
+func (c *Context) IsAborted() bool {
+	return c.index >= abortIndex
+}
+
+// AbortWithStatus 中断后续处理并写入状态码
+func (c *Context) AbortWithStatus(code int) {
+	c.Abort()
+	c.Status(code)
+}
+
+// AbortWithStatusJson 中断后续处理并返回 JSON 响应
+func (c *Context) AbortWithStatusJson(code int, obj interface{}) {
+	c.Abort()
+	c.Json(code, obj)
+}
+
+// Fail 中断后续处理并返回错误信息，Engine.ProblemDetails 为 true 时返回 problem+json
 func (c *Context) Fail(code int, err string) {
-	c.index = len(c.handlers)
-	c.Json(code, H{"message": err})
+	if c.useProblemDetails() {
+		c.AbortWithProblem(NewProblem(code, err))
+		return
+	}
+	c.AbortWithStatusJson(code, H{"message": err})
 }
 
 // 为了简化接口，封装了一些http.Request方法以供使用
 
-// PostForm 访问Query和PostForm参数的方法
-func (c *Context) PostForm(key string) string {
-	return c.Req.FormValue(key)
+// Query 和 PostForm 参数在第一次访问时解析并缓存在 Context 中，之后的访问不需要重复解析
+// 每种参数都提供了几种访问方式，以 Query 为例：
+//	Query         参数不存在时返回空字符串
+//	DefaultQuery  参数不存在时返回默认值
+//	GetQuery      第二个返回值表示参数是否存在，可以区分"不存在"和"值为空"
+//	QueryArray    返回同名参数的所有值，例如 ?id=1&id=2
+//	QueryMap      返回 map 形式的参数，例如 ?filter[a]=1&filter[b]=2
+
+func (c *Context) initQueryCache() {
+	if c.queryCache == nil {
+		if c.Req != nil {
+			c.queryCache = c.Req.URL.Query()
+		} else {
+			c.queryCache = url.Values{}
+		}
+	}
 }
 
 // Query 访问Query和PostForm参数的方法
 func (c *Context) Query(key string) string {
-	return c.Req.URL.Query().Get(key)
+	value, _ := c.GetQuery(key)
+	return value
+}
+
+// DefaultQuery 参数不存在时返回 defaultValue
+func (c *Context) DefaultQuery(key, defaultValue string) string {
+	if value, ok := c.GetQuery(key); ok {
+		return value
+	}
+	return defaultValue
+}
+
+// GetQuery 返回参数的第一个值以及参数是否存在
+func (c *Context) GetQuery(key string) (string, bool) {
+	if values, ok := c.GetQueryArray(key); ok {
+		return values[0], ok
+	}
+	return "", false
+}
+
+// QueryArray 返回参数的所有值
+func (c *Context) QueryArray(key string) []string {
+	values, _ := c.GetQueryArray(key)
+	return values
+}
+
+// GetQueryArray 返回参数的所有值以及参数是否存在
+func (c *Context) GetQueryArray(key string) ([]string, bool) {
+	c.initQueryCache()
+	values, ok := c.queryCache[key]
+	return values, ok && len(values) > 0
+}
+
+// QueryMap 返回 key[xxx] 形式的参数组成的 map
+func (c *Context) QueryMap(key string) map[string]string {
+	dicts, _ := c.GetQueryMap(key)
+	return dicts
+}
+
+// GetQueryMap 返回 key[xxx] 形式的参数组成的 map 以及是否存在这样的参数
+func (c *Context) GetQueryMap(key string) (map[string]string, bool) {
+	c.initQueryCache()
+	return getMapFromValues(c.queryCache, key)
+}
+
+// initFormCache 解析请求体中的表单，包括 multipart 表单中的普通字段，不包含 Query 参数
+func (c *Context) initFormCache() {
+	if c.formCache == nil {
+		c.formCache = make(url.Values)
+		if err := c.Req.ParseMultipartForm(c.maxMultipartMemory()); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+			c.Error(err)
+		}
+		if c.Req.PostForm != nil {
+			c.formCache = c.Req.PostForm
+		}
+	}
+}
+
+// PostForm 访问Query和PostForm参数的方法，与 http.Request.FormValue 相同，请求体中的参数优先，参数不存在时返回空字符串
+// 只需要请求体中的参数时使用 GetPostForm、DefaultPostForm 等方法
+func (c *Context) PostForm(key string) string {
+	if value, ok := c.GetPostForm(key); ok {
+		return value
+	}
+	return c.Query(key)
+}
+
+// DefaultPostForm 参数不存在时返回 defaultValue
+func (c *Context) DefaultPostForm(key, defaultValue string) string {
+	if value, ok := c.GetPostForm(key); ok {
+		return value
+	}
+	return defaultValue
+}
+
+// GetPostForm 返回参数的第一个值以及参数是否存在
+func (c *Context) GetPostForm(key string) (string, bool) {
+	if values, ok := c.GetPostFormArray(key); ok {
+		return values[0], ok
+	}
+	return "", false
+}
+
+// PostFormArray 返回参数的所有值
+func (c *Context) PostFormArray(key string) []string {
+	values, _ := c.GetPostFormArray(key)
+	return values
+}
+
+// GetPostFormArray 返回参数的所有值以及参数是否存在
+func (c *Context) GetPostFormArray(key string) ([]string, bool) {
+	c.initFormCache()
+	values, ok := c.formCache[key]
+	return values, ok && len(values) > 0
+}
+
+// PostFormMap 返回 key[xxx] 形式的参数组成的 map
+func (c *Context) PostFormMap(key string) map[string]string {
+	dicts, _ := c.GetPostFormMap(key)
+	return dicts
+}
+
+// GetPostFormMap 返回 key[xxx] 形式的参数组成的 map 以及是否存在这样的参数
+func (c *Context) GetPostFormMap(key string) (map[string]string, bool) {
+	c.initFormCache()
+	return getMapFromValues(c.formCache, key)
+}
+
+// getMapFromValues 从 values 中找出所有 key[xxx] 形式的参数，以 xxx 为 key 组成 map
+func getMapFromValues(values url.Values, key string) (map[string]string, bool) {
+	dicts := make(map[string]string)
+	exist := false
+	for k, v := range values {
+		i := strings.IndexByte(k, '[')
+		if i < 1 || k[:i] != key || len(v) == 0 {
+			continue
+		}
+		if j := strings.IndexByte(k[i+1:], ']'); j >= 1 {
+			exist = true
+			dicts[k[i+1:][:j]] = v[0]
+		}
+	}
+	return dicts, exist
 }
 
 // 封装一些http.ResponseWriter方法使用，为了方便对于JSON、HTML等返回类型的支持，这些返回类型都是非常常见的，因此封装起来，减少调用的代码量
 
+// Status 设置状态码，真正发送要等到写入响应体或者请求处理结束
 func (c *Context) Status(code int) {
-	c.StatusCode = code
 	c.Writer.WriteHeader(code)
+	c.StatusCode = c.Writer.Status()
 }
 
 func (c *Context) SetHeader(key, value string) {
 	c.Writer.Header().Set(key, value)
 }
 
+// Render 写入状态码和 Content-Type，再由具体的 Render 写出响应体
+// 所有快速构造响应的方法最终都会调用这里，新增响应格式只需要实现 render.Render 接口
+func (c *Context) Render(code int, r render.Render) {
+	r.WriteContentType(c.Writer)
+	c.Status(code)
+	if !bodyAllowedForStatus(code) {
+		c.Writer.WriteHeaderNow()
+		return
+	}
+	if err := r.Render(c.Writer); err != nil {
+		// 状态码和部分响应体可能已经发出，不能再写入错误信息，只记录错误并中止后续处理
+		// 还没有写入任何内容时(例如序列化失败)，把状态码改为 500
+		c.Error(err).SetType(ErrorTypeRender)
+		if !c.Writer.Written() {
+			c.Status(http.StatusInternalServerError)
+		}
+		c.Abort()
+	}
+}
+
+// bodyAllowedForStatus 1xx、204 和 304 响应不允许携带响应体
+func bodyAllowedForStatus(status int) bool {
+	switch {
+	case status >= 100 && status <= 199:
+		return false
+	case status == http.StatusNoContent:
+		return false
+	case status == http.StatusNotModified:
+		return false
+	}
+	return true
+}
+
 // 快速构造String/Data/JSON/HTML响应的方法
 func (c *Context) String(code int, format string, values ...interface{}) {
-	c.SetHeader("Content-Type", "text/plain")
-	c.Status(code)
-	c.Writer.Write([]byte(fmt.Sprintf(format, values...)))
+	c.Render(code, render.String{Format: format, Data: values})
 }
 
 // Json 快速构造String/Data/JSON/HTML响应的方法
 func (c *Context) Json(code int, obj interface{}) {
-	c.SetHeader("Content-Type", "application/json")
-	c.Status(code)
-	encoder := json.NewEncoder(c.Writer)
-	if err := encoder.Encode(obj); err != nil {
-		http.Error(c.Writer, err.Error(), 500)
+	c.Render(code, render.JSON{Data: obj})
+}
+
+// IndentedJson 返回带缩进的 JSON，比较耗费带宽，建议只在调试时使用
+func (c *Context) IndentedJson(code int, obj interface{}) {
+	c.Render(code, render.IndentedJSON{Data: obj})
+}
+
+// SecureJson 返回数组时会加上 engine 配置的前缀(默认 while(1);)，防止 JSON 劫持
+func (c *Context) SecureJson(code int, obj interface{}) {
+	c.Render(code, render.SecureJSON{Prefix: c.engine.secureJsonPrefix, Data: obj})
+}
+
+// Jsonp 当 Query 中带有 callback 参数时返回 JSONP，否则返回普通 JSON
+// callback 不是合法的 JavaScript 标识符时返回 400
+func (c *Context) Jsonp(code int, obj interface{}) {
+	callback := c.Query("callback")
+	if callback == "" {
+		c.Json(code, obj)
+		return
 	}
+	if !render.ValidCallback(callback) {
+		c.Fail(http.StatusBadRequest, render.ErrInvalidCallback.Error())
+		return
+	}
+	c.Render(code, render.JSONP{Callback: callback, Data: obj})
+}
+
+// PureJson 不会把 <、>、& 转义成 unicode 字符
+func (c *Context) PureJson(code int, obj interface{}) {
+	c.Render(code, render.PureJSON{Data: obj})
+}
+
+// AsciiJson 把所有非 ASCII 字符转义成 \uXXXX
+func (c *Context) AsciiJson(code int, obj interface{}) {
+	c.Render(code, render.AsciiJSON{Data: obj})
+}
+
+// XML 返回 XML 格式的响应
+func (c *Context) XML(code int, obj interface{}) {
+	c.Render(code, render.XML{Data: obj})
+}
+
+// YAML 返回 YAML 格式的响应
+func (c *Context) YAML(code int, obj interface{}) {
+	c.Render(code, render.YAML{Data: obj})
 }
 
 // Data 快速构造String/Data/JSON/HTML响应的方法
 func (c *Context) Data(code int, data []byte) {
-	c.Status(code)
-	c.Writer.Write(data)
+	c.Render(code, render.Data{Data: data})
 }
 
 // HTML 快速构造String/Data/JSON/HTML响应的方法
 func (c *Context) HTML(code int, name string, data interface{}) {
-	c.SetHeader("Content-Type", "text/html")
-	c.Status(code)
-	if err := c.engine.htmlTemplates.ExecuteTemplate(c.Writer, name, data); err != nil {
-		c.Fail(500, err.Error())
+	c.Render(code, render.HTML{Template: c.engine.htmlTemplates, Name: name, Data: data})
+}
+
+// Set 保存一个只属于本次请求的键值对，例如鉴权中间件把当前用户保存下来供后面的 handler 使用
+func (c *Context) Set(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Keys == nil {
+		c.Keys = make(map[string]interface{})
 	}
+	c.Keys[key] = value
+}
+
+// Get 返回 key 对应的值，exists 表示是否存在
+func (c *Context) Get(key string) (value interface{}, exists bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	value, exists = c.Keys[key]
+	return
+}
+
+// MustGet 返回 key 对应的值，不存在时 panic
+func (c *Context) MustGet(key string) interface{} {
+	if value, exists := c.Get(key); exists {
+		return value
+	}
+	panic("Key \"" + key + "\" does not exist")
+}
+
+// FullPath 返回匹配到的路由，例如 /user/:name，没有匹配到路由时返回空字符串
+func (c *Context) FullPath() string {
+	return c.fullPath
 }
 
 func (c *Context) Param(key string) string {
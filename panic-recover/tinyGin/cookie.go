@@ -0,0 +1,208 @@
+package tinyGin
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+/*
+Cookie 相关的方法。
+普通 Cookie 的值由客户端保存，可以被随意修改，因此提供了两种受保护的 Cookie：
+	签名 Cookie(SetSignedCookie)：值是明文，附带 HMAC-SHA256 签名，服务端可以发现篡改
+	加密 Cookie(SetEncryptedCookie)：值使用 AES-GCM 加密，客户端既看不到也无法篡改
+两者都需要先通过 Engine.SetCookieSecrets 设置密钥。
+为了支持密钥轮换，可以设置多个密钥：第一个用于签名和加密，所有密钥都会用于校验和解密，
+这样更换密钥之后，用旧密钥签发的 Cookie 在过期之前依然有效。
+*/
+
+var (
+	// ErrCookieSecretMissing 没有设置密钥时无法使用签名和加密 Cookie
+	ErrCookieSecretMissing = errors.New("tinyGin: cookie secrets are not configured")
+	// ErrInvalidCookie Cookie 的签名校验或者解密失败
+	ErrInvalidCookie = errors.New("tinyGin: invalid cookie value")
+)
+
+// CookieOptions 设置 Cookie 时的可选项
+type CookieOptions struct {
+	Path        string // 为空时使用 /
+	Domain      string
+	MaxAge      int // 大于 0 时为有效秒数，小于 0 时立刻删除，等于 0 时为会话 Cookie
+	Secure      bool
+	HttpOnly    bool
+	SameSite    http.SameSite
+	Partitioned bool // CHIPS 分区 Cookie，要求 Secure
+}
+
+// Cookie 返回请求中名为 name 的 Cookie 的值，不存在时返回 http.ErrNoCookie
+func (c *Context) Cookie(name string) (string, error) {
+	cookie, err := c.Req.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	return url.QueryUnescape(cookie.Value)
+}
+
+// SetCookie 在响应中添加 Set-Cookie，value 会经过 URL 编码
+// SameSite=None 和 Partitioned 都要求 Secure，这里会自动开启
+func (c *Context) SetCookie(name, value string, opts CookieOptions) {
+	if opts.Path == "" {
+		opts.Path = "/"
+	}
+	if opts.SameSite == http.SameSiteNoneMode || opts.Partitioned {
+		opts.Secure = true
+	}
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:        name,
+		Value:       url.QueryEscape(value),
+		Path:        opts.Path,
+		Domain:      opts.Domain,
+		MaxAge:      opts.MaxAge,
+		Secure:      opts.Secure,
+		HttpOnly:    opts.HttpOnly,
+		SameSite:    opts.SameSite,
+		Partitioned: opts.Partitioned,
+	})
+}
+
+// SetSignedCookie 设置带签名的 Cookie
+func (c *Context) SetSignedCookie(name, value string, opts CookieOptions) error {
+	keys := c.engine.cookieKeys
+	if len(keys) == 0 {
+		return ErrCookieSecretMissing
+	}
+	signature := signCookie(keys[0].sign, name, value)
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(value)) + "." + signature
+	c.SetCookie(name, encoded, opts)
+	return nil
+}
+
+// SignedCookie 返回校验通过的签名 Cookie 的值，签名不正确时返回 ErrInvalidCookie
+func (c *Context) SignedCookie(name string) (string, error) {
+	keys := c.engine.cookieKeys
+	if len(keys) == 0 {
+		return "", ErrCookieSecretMissing
+	}
+	raw, err := c.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	encoded, signature, ok := strings.Cut(raw, ".")
+	if !ok {
+		return "", ErrInvalidCookie
+	}
+	value, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", ErrInvalidCookie
+	}
+	for _, key := range keys {
+		if hmac.Equal([]byte(signature), []byte(signCookie(key.sign, name, string(value)))) {
+			return string(value), nil
+		}
+	}
+	return "", ErrInvalidCookie
+}
+
+// SetEncryptedCookie 设置加密的 Cookie，Cookie 名作为附加数据参与认证，防止把一个 Cookie 的值挪到另一个 Cookie 上
+func (c *Context) SetEncryptedCookie(name, value string, opts CookieOptions) error {
+	keys := c.engine.cookieKeys
+	if len(keys) == 0 {
+		return ErrCookieSecretMissing
+	}
+	aead, err := newCookieAEAD(keys[0].encrypt)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(value), []byte(name))
+	c.SetCookie(name, base64.RawURLEncoding.EncodeToString(sealed), opts)
+	return nil
+}
+
+// EncryptedCookie 返回解密后的 Cookie 值，所有密钥都解密失败时返回 ErrInvalidCookie
+func (c *Context) EncryptedCookie(name string) (string, error) {
+	keys := c.engine.cookieKeys
+	if len(keys) == 0 {
+		return "", ErrCookieSecretMissing
+	}
+	raw, err := c.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return "", ErrInvalidCookie
+	}
+	for _, key := range keys {
+		aead, err := newCookieAEAD(key.encrypt)
+		if err != nil {
+			return "", err
+		}
+		if len(sealed) < aead.NonceSize() {
+			return "", ErrInvalidCookie
+		}
+		nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+		if plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(name)); err == nil {
+			return string(plaintext), nil
+		}
+	}
+	return "", ErrInvalidCookie
+}
+
+// cookieKey 由同一个密钥派生出的签名密钥和加密密钥，避免同一个密钥用于两种用途
+type cookieKey struct {
+	sign    []byte
+	encrypt []byte
+}
+
+func deriveCookieKey(secret []byte) cookieKey {
+	return cookieKey{
+		sign:    deriveKey(secret, "tinyGin cookie signing"),
+		encrypt: deriveKey(secret, "tinyGin cookie encryption"),
+	}
+}
+
+func deriveKey(secret []byte, label string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(label))
+	return mac.Sum(nil)
+}
+
+// signCookie Cookie 名前面加上长度，名字里也可以有 |，否则 (a|b, c) 和 (a, b|c) 的签名相同
+func signCookie(key []byte, name, value string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(strconv.Itoa(len(name)) + ":" + name + value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func newCookieAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// SetCookieSecrets 设置签名和加密 Cookie 使用的密钥
+// 第一个密钥用于签发新的 Cookie，其余的密钥只用于校验，更换密钥时把新密钥放在最前面即可
+func (e *Engine) SetCookieSecrets(secrets ...[]byte) {
+	keys := make([]cookieKey, 0, len(secrets))
+	for _, secret := range secrets {
+		if len(secret) == 0 {
+			panic("tinyGin: cookie secret must not be empty")
+		}
+		keys = append(keys, deriveCookieKey(secret))
+	}
+	e.cookieKeys = keys
+}
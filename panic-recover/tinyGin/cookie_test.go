@@ -0,0 +1,132 @@
+package tinyGin
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// roundTrip 把 set 写入的 Cookie 带到新的请求中，再交给 get 处理
+func roundTrip(e *Engine, set, get HandlerFunc, tamper func(string) string) *httptest.ResponseRecorder {
+	e.GET("/set", set)
+	e.GET("/get", get)
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest("GET", "/set", nil))
+	req := httptest.NewRequest("GET", "/get", nil)
+	for _, cookie := range w.Result().Cookies() {
+		if tamper != nil {
+			cookie.Value = tamper(cookie.Value)
+		}
+		req.AddCookie(cookie)
+	}
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	return w
+}
+
+func TestSignedCookie(t *testing.T) {
+	set := func(c *Context) {
+		c.SetSignedCookie("user", "amadeus", CookieOptions{HttpOnly: true})
+	}
+	get := func(c *Context) {
+		value, err := c.SignedCookie("user")
+		if err != nil {
+			c.String(http.StatusUnauthorized, err.Error())
+			return
+		}
+		c.String(http.StatusOK, value)
+	}
+
+	e := New()
+	e.SetCookieSecrets([]byte("secret"))
+	if w := roundTrip(e, set, get, nil); w.Body.String() != "amadeus" {
+		t.Fatalf("unexpected value %q", w.Body.String())
+	}
+
+	e = New()
+	e.SetCookieSecrets([]byte("secret"))
+	w := roundTrip(e, set, get, func(v string) string {
+		return "YWRtaW4" + v[strings.Index(v, "."):] // 把值换成 admin
+	})
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("tampered cookie should be rejected, got %d %q", w.Code, w.Body.String())
+	}
+
+	// 密钥轮换之后，旧密钥签发的 Cookie 依然有效
+	old := New()
+	old.SetCookieSecrets([]byte("old-secret"))
+	old.GET("/set", set)
+	setResp := httptest.NewRecorder()
+	old.ServeHTTP(setResp, httptest.NewRequest("GET", "/set", nil))
+
+	rotated := New()
+	rotated.SetCookieSecrets([]byte("new-secret"), []byte("old-secret"))
+	rotated.GET("/get", get)
+	req := httptest.NewRequest("GET", "/get", nil)
+	req.AddCookie(setResp.Result().Cookies()[0])
+	w = httptest.NewRecorder()
+	rotated.ServeHTTP(w, req)
+	if w.Body.String() != "amadeus" {
+		t.Fatalf("rotated key should still verify, got %q", w.Body.String())
+	}
+}
+
+func TestSignedCookieBoundToName(t *testing.T) {
+	e := New()
+	e.SetCookieSecrets([]byte("secret"))
+	e.GET("/set", func(c *Context) {
+		c.SetSignedCookie("a", "b|c", CookieOptions{})
+	})
+	e.GET("/get", func(c *Context) {
+		if _, err := c.SignedCookie("a|b"); err != ErrInvalidCookie {
+			c.String(http.StatusOK, "accepted")
+		}
+	})
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest("GET", "/set", nil))
+	_, signature, _ := strings.Cut(w.Result().Cookies()[0].Value, ".")
+	// 把 (a, b|c) 的签名挪到 (a|b, c) 上
+	req := httptest.NewRequest("GET", "/get", nil)
+	req.AddCookie(&http.Cookie{Name: "a|b", Value: base64.RawURLEncoding.EncodeToString([]byte("c")) + "." + signature})
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	if w.Body.String() == "accepted" {
+		t.Fatal("a value signed for one cookie name should not verify under another")
+	}
+}
+
+func TestEncryptedCookie(t *testing.T) {
+	e := New()
+	e.SetCookieSecrets([]byte("secret"))
+	var raw string
+	w := roundTrip(e, func(c *Context) {
+		c.SetEncryptedCookie("session", "top secret", CookieOptions{SameSite: http.SameSiteNoneMode})
+	}, func(c *Context) {
+		raw, _ = c.Cookie("session")
+		value, err := c.EncryptedCookie("session")
+		if err != nil {
+			c.String(http.StatusUnauthorized, err.Error())
+			return
+		}
+		c.String(http.StatusOK, value)
+	}, nil)
+	if w.Body.String() != "top secret" {
+		t.Fatalf("unexpected value %q", w.Body.String())
+	}
+	if strings.Contains(raw, "secret") {
+		t.Fatalf("cookie value should be encrypted: %q", raw)
+	}
+}
+
+func TestSetCookieOptions(t *testing.T) {
+	w := httptest.NewRecorder()
+	c := newContext(w, httptest.NewRequest("GET", "/", nil))
+	c.SetCookie("a", "b c", CookieOptions{Partitioned: true, SameSite: http.SameSiteLaxMode, MaxAge: 60})
+	got := w.Header().Get("Set-Cookie")
+	want := "a=b+c; Path=/; Max-Age=60; Secure; SameSite=Lax; Partitioned"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
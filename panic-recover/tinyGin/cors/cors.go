@@ -0,0 +1,228 @@
+package cors
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"tinyGin"
+)
+
+/*
+cors 实现跨域资源共享(CORS)中间件。
+
+	r.Use(cors.New(cors.Config{
+		AllowOrigins:     []string{"https://app.example.com", "https://*.example.com"},
+		AllowHeaders:     []string{"Content-Type", "Authorization"},
+		AllowCredentials: true,
+		MaxAge:           12 * time.Hour,
+	}))
+
+浏览器发送跨域的非简单请求前会先发送 OPTIONS 预检请求，中间件直接以 204 响应预检请求并中止后续处理，
+所以不需要为每个路由注册 OPTIONS 方法：路由没有匹配时中间件同样会执行。
+中间件要通过 Engine.Use 注册在最外层，挂在分组上时只对分组前缀下的路径生效。
+*/
+
+// Config CORS 中间件的配置
+type Config struct {
+	// AllowOrigins 允许的来源，可以是完整的来源(https://app.example.com)、
+	// 带通配符的子域名(https://*.example.com，不包括 example.com 本身)或者 * (允许所有来源)
+	AllowOrigins []string
+	// AllowOriginFunc 自定义来源检查，AllowOrigins 都不匹配时调用
+	AllowOriginFunc func(origin string) bool
+	// AllowMethods 预检请求中返回的允许的请求方法，默认为 GET、POST、PUT、PATCH、DELETE、HEAD
+	AllowMethods []string
+	// AllowHeaders 预检请求中返回的允许的请求头，默认为 Origin、Content-Length、Content-Type
+	AllowHeaders []string
+	// ExposeHeaders 允许浏览器中的脚本读取的响应头
+	ExposeHeaders []string
+	// AllowCredentials 是否允许携带 Cookie 等凭据，此时 Access-Control-Allow-Origin 返回请求的来源
+	// 不能与 * 同时使用，否则任何网站都能带着用户的 Cookie 读取响应
+	AllowCredentials bool
+	// MaxAge 预检请求结果的缓存时间，为 0 时不返回 Access-Control-Max-Age
+	MaxAge time.Duration
+}
+
+// DefaultConfig 允许所有来源，不允许携带凭据
+func DefaultConfig() Config {
+	return Config{
+		AllowOrigins: []string{"*"},
+		MaxAge:       12 * time.Hour,
+	}
+}
+
+// Default 使用 DefaultConfig 的 CORS 中间件
+func Default() tinyGin.HandlerFunc {
+	return New(DefaultConfig())
+}
+
+// wildcardOrigin 带通配符的来源，例如 https://*.example.com 拆分为 https:// 和 .example.com
+type wildcardOrigin struct {
+	prefix, suffix string
+}
+
+func (w wildcardOrigin) match(origin string) bool {
+	if len(origin) <= len(w.prefix)+len(w.suffix) || !strings.HasPrefix(origin, w.prefix) || !strings.HasSuffix(origin, w.suffix) {
+		return false
+	}
+	// 通配符只能匹配域名部分，不能包含路径和端口
+	sub := origin[len(w.prefix) : len(origin)-len(w.suffix)]
+	return !strings.ContainsAny(sub, "/:")
+}
+
+// cors 解析之后的配置
+type cors struct {
+	allowAll      bool
+	origins       map[string]struct{}
+	wildcards     []wildcardOrigin
+	originFunc    func(string) bool
+	credentials   bool
+	allowMethods  string
+	allowHeaders  string
+	exposeHeaders string
+	maxAge        string
+}
+
+// New 按照 conf 创建 CORS 中间件，conf 中没有任何允许的来源、来源格式不正确或者 * 与 AllowCredentials 同时使用时 panic
+func New(conf Config) tinyGin.HandlerFunc {
+	if len(conf.AllowOrigins) == 0 && conf.AllowOriginFunc == nil {
+		panic("cors: AllowOrigins or AllowOriginFunc is required")
+	}
+	cs := &cors{
+		origins:     make(map[string]struct{}),
+		originFunc:  conf.AllowOriginFunc,
+		credentials: conf.AllowCredentials,
+	}
+	for _, origin := range conf.AllowOrigins {
+		origin = strings.ToLower(strings.TrimSpace(origin))
+		switch strings.Count(origin, "*") {
+		case 0:
+			cs.origins[origin] = struct{}{}
+		case 1:
+			if origin == "*" {
+				cs.allowAll = true
+				continue
+			}
+			i := strings.Index(origin, "://*.")
+			if i < 0 {
+				panic("cors: invalid wildcard origin " + origin)
+			}
+			cs.wildcards = append(cs.wildcards, wildcardOrigin{prefix: origin[:i+3], suffix: origin[i+4:]})
+		default:
+			panic("cors: invalid wildcard origin " + origin)
+		}
+	}
+	if cs.allowAll && cs.credentials {
+		panic("cors: AllowOrigins * cannot be used with AllowCredentials")
+	}
+
+	methods := conf.AllowMethods
+	if len(methods) == 0 {
+		methods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodHead}
+	}
+	headers := conf.AllowHeaders
+	if len(headers) == 0 {
+		headers = []string{"Origin", "Content-Length", "Content-Type"}
+	}
+	cs.allowMethods = strings.ToUpper(strings.Join(methods, ", "))
+	cs.allowHeaders = joinHeaders(headers)
+	cs.exposeHeaders = joinHeaders(conf.ExposeHeaders)
+	if conf.MaxAge > 0 {
+		cs.maxAge = strconv.FormatInt(int64(conf.MaxAge/time.Second), 10)
+	}
+	return cs.handle
+}
+
+// joinHeaders 规范化请求头的名称并用逗号连接
+func joinHeaders(headers []string) string {
+	canonical := make([]string, 0, len(headers))
+	for _, h := range headers {
+		canonical = append(canonical, http.CanonicalHeaderKey(strings.TrimSpace(h)))
+	}
+	return strings.Join(canonical, ", ")
+}
+
+func (cs *cors) handle(c *tinyGin.Context) {
+	origin := c.Req.Header.Get("Origin")
+	if origin == "" || sameOrigin(origin, c.Req) {
+		// 不是跨域请求
+		c.Next()
+		return
+	}
+	header := c.Writer.Header()
+	// 响应的内容与 Origin 有关，缓存需要区分不同的来源
+	if !cs.allowAll {
+		header.Add("Vary", "Origin")
+	}
+	preflight := c.Method == http.MethodOptions && c.Req.Header.Get("Access-Control-Request-Method") != ""
+	if preflight {
+		header.Add("Vary", "Access-Control-Request-Method")
+		header.Add("Vary", "Access-Control-Request-Headers")
+	}
+	if !cs.allowOrigin(origin) {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	if cs.allowAll {
+		header.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		header.Set("Access-Control-Allow-Origin", origin)
+	}
+	if cs.credentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+	if !preflight {
+		if cs.exposeHeaders != "" {
+			header.Set("Access-Control-Expose-Headers", cs.exposeHeaders)
+		}
+		c.Next()
+		return
+	}
+
+	// 预检请求直接响应，不再交给路由处理
+	header.Set("Access-Control-Allow-Methods", cs.allowMethods)
+	header.Set("Access-Control-Allow-Headers", cs.allowHeaders)
+	if cs.maxAge != "" {
+		header.Set("Access-Control-Max-Age", cs.maxAge)
+	}
+	c.AbortWithStatus(http.StatusNoContent)
+}
+
+// allowOrigin 判断来源是否被允许
+func (cs *cors) allowOrigin(origin string) bool {
+	if cs.allowAll {
+		return true
+	}
+	lower := strings.ToLower(origin)
+	if _, ok := cs.origins[lower]; ok {
+		return true
+	}
+	for _, w := range cs.wildcards {
+		if w.match(lower) {
+			return true
+		}
+	}
+	return cs.originFunc != nil && cs.originFunc(origin)
+}
+
+// sameOrigin 判断 Origin 是否就是服务本身，同源请求中浏览器也可能带上 Origin
+// 同源要求协议和主机都相同，http 页面访问同一主机的 https 接口仍然是跨域请求
+func sameOrigin(origin string, req *http.Request) bool {
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Scheme, requestScheme(req)) && strings.EqualFold(u.Host, req.Host)
+}
+
+// requestScheme 返回请求使用的协议，TLS 在反向代理处终止时使用 X-Forwarded-Proto
+// 伪造的 X-Forwarded-Proto 最多让请求被当作同源而不返回 CORS 响应头，不会放宽限制
+func requestScheme(req *http.Request) string {
+	if proto := req.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme, _, _ := strings.Cut(proto, ",")
+		return strings.TrimSpace(scheme)
+	}
+	if req.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
@@ -0,0 +1,130 @@
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"tinyGin"
+)
+
+func newServer(conf Config) *tinyGin.Engine {
+	r := tinyGin.New()
+	r.Use(New(conf))
+	r.GET("/users", func(c *tinyGin.Context) {
+		c.SetHeader("X-Total-Count", "1")
+		c.String(http.StatusOK, "users")
+	})
+	return r
+}
+
+func request(r *tinyGin.Engine, method, origin string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/users", nil)
+	req.Host = "api.internal"
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestPreflight(t *testing.T) {
+	r := newServer(Config{
+		AllowOrigins:     []string{"https://app.example.com"},
+		AllowMethods:     []string{"get", "post"},
+		AllowHeaders:     []string{"content-type", "authorization"},
+		AllowCredentials: true,
+		MaxAge:           time.Hour,
+	})
+	// 没有注册 OPTIONS 路由，预检请求也能得到响应
+	w := request(r, http.MethodOptions, "https://app.example.com", map[string]string{"Access-Control-Request-Method": "POST"})
+	h := w.Header()
+	if w.Code != http.StatusNoContent || w.Body.Len() != 0 {
+		t.Fatalf("unexpected preflight response %d %q", w.Code, w.Body.String())
+	}
+	if h.Get("Access-Control-Allow-Origin") != "https://app.example.com" || h.Get("Access-Control-Allow-Credentials") != "true" ||
+		h.Get("Access-Control-Allow-Methods") != "GET, POST" || h.Get("Access-Control-Allow-Headers") != "Content-Type, Authorization" ||
+		h.Get("Access-Control-Max-Age") != "3600" {
+		t.Fatalf("unexpected preflight headers %v", h)
+	}
+	if vary := strings.Join(h.Values("Vary"), ","); !strings.Contains(vary, "Origin") || !strings.Contains(vary, "Access-Control-Request-Method") {
+		t.Fatalf("unexpected Vary %q", vary)
+	}
+
+	w = request(r, http.MethodOptions, "https://evil.com", map[string]string{"Access-Control-Request-Method": "POST"})
+	if w.Code != http.StatusForbidden || w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatalf("disallowed origin: %d %v", w.Code, w.Header())
+	}
+}
+
+func TestActualRequest(t *testing.T) {
+	r := newServer(Config{
+		AllowOrigins:  []string{"https://*.example.com"},
+		ExposeHeaders: []string{"x-total-count"},
+		AllowOriginFunc: func(origin string) bool {
+			return origin == "http://localhost:3000"
+		},
+	})
+	cases := []struct {
+		origin string
+		status int
+		allow  string
+	}{
+		{"", http.StatusOK, ""},
+		{"https://api.example.com", http.StatusOK, "https://api.example.com"},
+		{"https://a.b.example.com", http.StatusOK, "https://a.b.example.com"},
+		{"http://localhost:3000", http.StatusOK, "http://localhost:3000"},
+		{"https://example.com", http.StatusForbidden, ""},
+		{"https://evil.com/.example.com", http.StatusForbidden, ""},
+		{"http://api.example.com", http.StatusForbidden, ""},
+	}
+	for _, tc := range cases {
+		w := request(r, http.MethodGet, tc.origin, nil)
+		if w.Code != tc.status || w.Header().Get("Access-Control-Allow-Origin") != tc.allow {
+			t.Errorf("%q: got %d %q", tc.origin, w.Code, w.Header().Get("Access-Control-Allow-Origin"))
+		}
+		if tc.allow != "" && w.Header().Get("Access-Control-Expose-Headers") != "X-Total-Count" {
+			t.Errorf("%q: missing expose headers", tc.origin)
+		}
+	}
+}
+
+func TestDefault(t *testing.T) {
+	r := tinyGin.New()
+	r.Use(Default())
+	r.GET("/users", func(c *tinyGin.Context) {})
+	w := request(r, http.MethodGet, "https://anywhere.com", nil)
+	if w.Header().Get("Access-Control-Allow-Origin") != "*" || w.Header().Get("Vary") != "" {
+		t.Fatalf("unexpected headers %v", w.Header())
+	}
+}
+
+func TestNewRejectsWildcardWithCredentials(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("* with AllowCredentials should panic")
+		}
+	}()
+	New(Config{AllowOrigins: []string{"*"}, AllowCredentials: true})
+}
+
+func TestSameOrigin(t *testing.T) {
+	r := newServer(Config{AllowOrigins: []string{"https://app.example.com"}})
+	// 协议和主机都相同才是同源请求，直接交给路由处理
+	if w := request(r, http.MethodGet, "http://api.internal", nil); w.Code != http.StatusOK {
+		t.Fatalf("same origin request should pass, got %d", w.Code)
+	}
+	// 协议不同是跨域请求，来源不在允许列表中
+	if w := request(r, http.MethodGet, "https://api.internal", nil); w.Code != http.StatusForbidden {
+		t.Fatalf("different scheme should be cross-origin, got %d", w.Code)
+	}
+	w := request(r, http.MethodGet, "https://api.internal", map[string]string{"X-Forwarded-Proto": "https"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("X-Forwarded-Proto should be used as the request scheme, got %d", w.Code)
+	}
+}
@@ -0,0 +1,235 @@
+package tinyGin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+/*
+handler 在处理过程中可以通过 c.Error(err) 记录错误，而不是立刻返回响应，
+这些错误保存在 c.Errors 中，由 Logger、ErrorHandler 等中间件在 Next 返回之后统一处理。
+*/
+
+// ErrorType 错误的类型，使用位标记，可以组合使用
+type ErrorType uint64
+
+const (
+	// ErrorTypeBind 参数解析或校验失败
+	ErrorTypeBind ErrorType = 1 << 63
+	// ErrorTypeRender 渲染响应失败
+	ErrorTypeRender ErrorType = 1 << 62
+	// ErrorTypePrivate 内部错误，不应该把细节返回给客户端
+	ErrorTypePrivate ErrorType = 1 << 0
+	// ErrorTypePublic 可以把错误信息返回给客户端
+	ErrorTypePublic ErrorType = 1 << 1
+	// ErrorTypeAny 匹配所有类型
+	ErrorTypeAny ErrorType = 1<<64 - 1
+)
+
+// Error 在原始错误的基础上附加了类型和元数据
+type Error struct {
+	Err  error
+	Type ErrorType
+	Meta interface{}
+}
+
+type errorMsgs []*Error
+
+var _ error = &Error{}
+
+// SetType 设置错误类型
+func (msg *Error) SetType(flags ErrorType) *Error {
+	msg.Type = flags
+	return msg
+}
+
+// SetMeta 设置错误的元数据，例如出错的参数
+func (msg *Error) SetMeta(data interface{}) *Error {
+	msg.Meta = data
+	return msg
+}
+
+// JSON 返回可以序列化为 JSON 的错误描述
+func (msg *Error) JSON() interface{} {
+	jsonData := H{}
+	if msg.Meta != nil {
+		value := reflect.ValueOf(msg.Meta)
+		switch value.Kind() {
+		case reflect.Struct:
+			return msg.Meta
+		case reflect.Map:
+			for _, key := range value.MapKeys() {
+				jsonData[fmt.Sprint(key.Interface())] = value.MapIndex(key).Interface()
+			}
+		default:
+			jsonData["meta"] = msg.Meta
+		}
+	}
+	if _, ok := jsonData["error"]; !ok {
+		jsonData["error"] = msg.Error()
+	}
+	return jsonData
+}
+
+// MarshalJSON 实现 json.Marshaller 接口
+func (msg *Error) MarshalJSON() ([]byte, error) {
+	return json.Marshal(msg.JSON())
+}
+
+// Error 实现 error 接口
+func (msg *Error) Error() string {
+	return msg.Err.Error()
+}
+
+// IsType 判断错误是否属于 flags 中的类型
+func (msg *Error) IsType(flags ErrorType) bool {
+	return (msg.Type & flags) > 0
+}
+
+// Unwrap 返回原始错误，支持 errors.Is 和 errors.As
+func (msg *Error) Unwrap() error {
+	return msg.Err
+}
+
+// ByType 返回指定类型的错误
+func (a errorMsgs) ByType(typ ErrorType) errorMsgs {
+	if len(a) == 0 {
+		return nil
+	}
+	if typ == ErrorTypeAny {
+		return a
+	}
+	var result errorMsgs
+	for _, msg := range a {
+		if msg.IsType(typ) {
+			result = append(result, msg)
+		}
+	}
+	return result
+}
+
+// Last 返回最后一个错误，没有错误时返回 nil
+func (a errorMsgs) Last() *Error {
+	if length := len(a); length > 0 {
+		return a[length-1]
+	}
+	return nil
+}
+
+// Errors 返回所有错误信息
+func (a errorMsgs) Errors() []string {
+	if len(a) == 0 {
+		return nil
+	}
+	errorStrings := make([]string, len(a))
+	for i, err := range a {
+		errorStrings[i] = err.Error()
+	}
+	return errorStrings
+}
+
+// JSON 只有一个错误时返回该错误的描述，多个错误时返回数组
+func (a errorMsgs) JSON() interface{} {
+	switch length := len(a); length {
+	case 0:
+		return nil
+	case 1:
+		return a.Last().JSON()
+	default:
+		jsonData := make([]interface{}, length)
+		for i, err := range a {
+			jsonData[i] = err.JSON()
+		}
+		return jsonData
+	}
+}
+
+// MarshalJSON 实现 json.Marshaller 接口
+func (a errorMsgs) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.JSON())
+}
+
+// String 每个错误一行，便于输出到日志
+func (a errorMsgs) String() string {
+	if len(a) == 0 {
+		return ""
+	}
+	var buffer strings.Builder
+	for i, msg := range a {
+		fmt.Fprintf(&buffer, "Error #%02d: %s\n", i+1, msg.Err)
+		if msg.Meta != nil {
+			fmt.Fprintf(&buffer, "     Meta: %v\n", msg.Meta)
+		}
+	}
+	return buffer.String()
+}
+
+// HTTPError 携带状态码的错误，ErrorHandler 会按照 Code 和 Message 返回响应
+type HTTPError struct {
+	Code    int
+	Message string
+}
+
+// NewHTTPError 创建 HTTPError，message 为空时使用状态码对应的描述
+func NewHTTPError(code int, message string) *HTTPError {
+	if message == "" {
+		message = http.StatusText(code)
+	}
+	return &HTTPError{Code: code, Message: message}
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("code=%d, message=%s", e.Code, e.Message)
+}
+
+// Error 记录一个错误，返回的 *Error 可以继续设置类型和元数据
+// 错误默认是 ErrorTypePrivate 类型，不会把细节返回给客户端
+func (c *Context) Error(err error) *Error {
+	if err == nil {
+		panic("err is nil")
+	}
+	var parsedError *Error
+	if !errors.As(err, &parsedError) {
+		parsedError = &Error{
+			Err:  err,
+			Type: ErrorTypePrivate,
+		}
+	}
+	c.Errors = append(c.Errors, parsedError)
+	return parsedError
+}
+
+// ErrorHandler 在后续 handler 执行完毕后，根据 c.Errors 中的最后一个错误返回响应
+// 如果 handler 已经写入了响应，则不再处理
+//   - Problem 原样以 problem+json 返回
+//   - HTTPError 按照 Code 和 Message 返回
+//   - 参数解析或校验错误返回 400
+//   - ErrorTypePublic 类型的错误返回 500 以及错误信息
+//   - 其他错误返回 500 Internal Server Error，不暴露内部细节
+func ErrorHandler() HandlerFunc {
+	return func(c *Context) {
+		c.Next()
+		last := c.Errors.Last()
+		if last == nil || c.Writer.Written() {
+			return
+		}
+		var problem *Problem
+		var httpErr *HTTPError
+		switch {
+		case errors.As(last.Err, &problem):
+			c.AbortWithProblem(problem)
+		case errors.As(last.Err, &httpErr):
+			c.Fail(httpErr.Code, httpErr.Message)
+		case last.IsType(ErrorTypeBind):
+			c.AbortWithBindError(last.Err)
+		case last.IsType(ErrorTypePublic):
+			c.Fail(http.StatusInternalServerError, last.Error())
+		default:
+			c.Fail(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package tinyGin
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestContextError(t *testing.T) {
+	c := newContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	c.Error(errors.New("first"))
+	c.Error(errors.New("second")).SetType(ErrorTypePublic).SetMeta("meta")
+	if len(c.Errors) != 2 || c.Errors.Last().Error() != "second" {
+		t.Fatalf("unexpected errors: %v", c.Errors.Errors())
+	}
+	if public := c.Errors.ByType(ErrorTypePublic); len(public) != 1 {
+		t.Fatalf("expected 1 public error, got %d", len(public))
+	}
+	want := "Error #01: first\nError #02: second\n     Meta: meta\n"
+	if c.Errors.String() != want {
+		t.Fatalf("unexpected String(): %q", c.Errors.String())
+	}
+}
+
+func TestErrorHandler(t *testing.T) {
+	r := New()
+	r.Use(ErrorHandler())
+	r.GET("/http", func(c *Context) {
+		c.Error(NewHTTPError(http.StatusForbidden, "no access"))
+	})
+	r.GET("/private", func(c *Context) {
+		c.Error(errors.New("database password leaked"))
+	})
+	r.GET("/written", func(c *Context) {
+		c.String(http.StatusOK, "ok")
+		c.Error(errors.New("ignored"))
+	})
+	cases := []struct {
+		path string
+		code int
+		body string
+	}{
+		{"/http", http.StatusForbidden, `{"message":"no access"}`},
+		{"/private", http.StatusInternalServerError, `{"message":"Internal Server Error"}`},
+		{"/written", http.StatusOK, "ok"},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", tc.path, nil))
+		if w.Code != tc.code || w.Body.String() != tc.body {
+			t.Errorf("%s: got %d %q", tc.path, w.Code, w.Body.String())
+		}
+	}
+}
+
+// partialRender 写入一部分响应体之后失败
+type partialRender struct{}
+
+func (partialRender) Render(w http.ResponseWriter) error {
+	w.Write([]byte(`{"items":[`))
+	return errors.New("broken stream")
+}
+
+func (partialRender) WriteContentType(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", MIMEJSON)
+}
+
+func TestRenderErrorDoesNotWriteAgain(t *testing.T) {
+	r := New()
+	r.GET("/partial", func(c *Context) {
+		c.Render(http.StatusOK, partialRender{})
+		if !c.IsAborted() || c.Errors.ByType(ErrorTypeRender).Last().Error() != "broken stream" {
+			t.Errorf("render error should be recorded and abort the chain")
+		}
+	})
+	r.GET("/marshal", func(c *Context) {
+		c.Json(http.StatusOK, H{"ch": make(chan int)})
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/partial", nil))
+	if w.Code != http.StatusOK || w.Body.String() != `{"items":[` {
+		t.Fatalf("nothing should be written after a failed render: %d %q", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/marshal", nil))
+	if w.Code != http.StatusInternalServerError || w.Body.Len() != 0 {
+		t.Fatalf("unwritten response should become 500: %d %q", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,177 @@
+package tinyGin
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"tinyGin/render"
+)
+
+/*
+文件响应。
+File、FileFromFS 和 FileAttachment 都交给 net/http 的文件服务处理，自动支持 Range、If-Modified-Since 等请求头；
+DataFromReader 用于返回动态生成的内容，reader 可以 Seek 时同样支持 Range 请求。
+*/
+
+// File 返回服务器上的文件
+func (c *Context) File(filepath string) {
+	http.ServeFile(c.Writer, c.Req, filepath)
+}
+
+// FileFromFS 返回 fs 中的文件，例如 embed.FS 中打包的静态资源
+func (c *Context) FileFromFS(filepath string, fs http.FileSystem) {
+	defer func(old string) {
+		c.Req.URL.Path = old
+	}(c.Req.URL.Path)
+	c.Req.URL.Path = filepath
+	http.FileServer(fs).ServeHTTP(c.Writer, c.Req)
+}
+
+// FileAttachment 以附件的形式返回文件，浏览器会弹出下载框，保存的文件名为 filename
+func (c *Context) FileAttachment(filepath, filename string) {
+	c.SetHeader("Content-Disposition", contentDisposition("attachment", filename))
+	http.ServeFile(c.Writer, c.Req, filepath)
+}
+
+// DataFromReader 将 reader 中的内容写入响应，contentLength 小于 0 表示长度未知
+// 状态码为 200 且 reader 实现了 io.ReadSeeker 时支持 Range 请求，contentLength 不小于 0 时只返回从当前位置开始的 contentLength 个字节
+// extraHeaders 不会覆盖中间件已经设置的同名 Header
+func (c *Context) DataFromReader(code int, contentLength int64, contentType string, reader io.Reader, extraHeaders map[string]string) {
+	if rs, ok := reader.(io.ReadSeeker); ok && code == http.StatusOK {
+		header := c.Writer.Header()
+		if contentType != "" && header.Get("Content-Type") == "" {
+			header.Set("Content-Type", contentType)
+		}
+		for k, v := range extraHeaders {
+			if header.Get(k) == "" {
+				header.Set(k, v)
+			}
+		}
+		if contentLength >= 0 {
+			limited, err := newLimitReadSeeker(rs, contentLength)
+			if err != nil {
+				c.Error(err).SetType(ErrorTypeRender)
+				c.Fail(http.StatusInternalServerError, "Internal Server Error")
+				return
+			}
+			rs = limited
+		}
+		http.ServeContent(c.Writer, c.Req, "", time.Time{}, rs)
+		return
+	}
+	c.Render(code, render.Reader{
+		ContentType:   contentType,
+		ContentLength: contentLength,
+		Reader:        reader,
+		Headers:       extraHeaders,
+	})
+}
+
+// limitReadSeeker 只暴露 ReadSeeker 从当前位置开始的 n 个字节，http.ServeContent 通过 Seek 到末尾得到内容的长度
+type limitReadSeeker struct {
+	rs     io.ReadSeeker
+	base   int64 // 在 rs 中的起始位置
+	offset int64 // 相对于 base 的当前位置
+	n      int64
+}
+
+func newLimitReadSeeker(rs io.ReadSeeker, n int64) (*limitReadSeeker, error) {
+	base, err := rs.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	end, err := rs.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := rs.Seek(base, io.SeekStart); err != nil {
+		return nil, err
+	}
+	// contentLength 比剩余的内容还长时以实际长度为准
+	return &limitReadSeeker{rs: rs, base: base, n: min(n, end-base)}, nil
+}
+
+func (l *limitReadSeeker) Read(p []byte) (int, error) {
+	if l.offset >= l.n {
+		return 0, io.EOF
+	}
+	if remain := l.n - l.offset; int64(len(p)) > remain {
+		p = p[:remain]
+	}
+	n, err := l.rs.Read(p)
+	l.offset += int64(n)
+	return n, err
+}
+
+func (l *limitReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += l.offset
+	case io.SeekEnd:
+		offset += l.n
+	default:
+		return 0, errors.New("tinyGin: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("tinyGin: negative position")
+	}
+	if _, err := l.rs.Seek(l.base+offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+	l.offset = offset
+	return offset, nil
+}
+
+// contentDisposition 按照 RFC 6266 生成 Content-Disposition
+// 文件名只包含 ASCII 字符时直接使用 filename 参数，否则额外使用 RFC 5987 编码的 filename* 参数，
+// filename 参数中的非 ASCII 字符替换成 _，供不支持 filename* 的旧客户端使用
+func contentDisposition(dispositionType, filename string) string {
+	var fallback strings.Builder
+	ascii := true
+	for _, r := range filename {
+		switch {
+		case r > 0x7e || r < 0x20:
+			ascii = false
+			fallback.WriteByte('_')
+		case r == '"' || r == '\\':
+			fallback.WriteByte('\\')
+			fallback.WriteRune(r)
+		default:
+			fallback.WriteRune(r)
+		}
+	}
+	value := dispositionType + `; filename="` + fallback.String() + `"`
+	if !ascii {
+		value += "; filename*=UTF-8''" + encodeRFC5987(filename)
+	}
+	return value
+}
+
+// encodeRFC5987 对 attr-char 之外的字节做百分号编码
+func encodeRFC5987(s string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if isAttrChar(ch) {
+			b.WriteByte(ch)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[ch>>4])
+		b.WriteByte(hex[ch&0x0f])
+	}
+	return b.String()
+}
+
+// isAttrChar RFC 5987 中的 attr-char
+func isAttrChar(ch byte) bool {
+	switch {
+	case ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z', ch >= '0' && ch <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$&+-.^_`|~", ch) >= 0
+}
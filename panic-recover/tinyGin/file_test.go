@@ -0,0 +1,105 @@
+package tinyGin
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestContentDisposition(t *testing.T) {
+	cases := map[string]string{
+		"report.pdf":     `attachment; filename="report.pdf"`,
+		`a"b\c.txt`:      `attachment; filename="a\"b\\c.txt"`,
+		"报告 2024.pdf":    `attachment; filename="__ 2024.pdf"; filename*=UTF-8''%E6%8A%A5%E5%91%8A%202024.pdf`,
+		"naïve file.txt": `attachment; filename="na_ve file.txt"; filename*=UTF-8''na%C3%AFve%20file.txt`,
+	}
+	for name, want := range cases {
+		if got := contentDisposition("attachment", name); got != want {
+			t.Errorf("%q: got %s, want %s", name, got, want)
+		}
+	}
+}
+
+func TestFileAttachment(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.txt")
+	os.WriteFile(path, []byte("0123456789"), 0o644)
+	r := New()
+	r.GET("/download", func(c *Context) {
+		c.FileAttachment(path, "数据.txt")
+	})
+	req := httptest.NewRequest("GET", "/download", nil)
+	req.Header.Set("Range", "bytes=2-4")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusPartialContent || w.Body.String() != "234" {
+		t.Fatalf("unexpected response %d %q", w.Code, w.Body.String())
+	}
+	if cd := w.Header().Get("Content-Disposition"); cd != `attachment; filename="__.txt"; filename*=UTF-8''%E6%95%B0%E6%8D%AE.txt` {
+		t.Fatalf("unexpected Content-Disposition %s", cd)
+	}
+}
+
+func TestDataFromReader(t *testing.T) {
+	r := New()
+	r.GET("/seekable", func(c *Context) {
+		c.DataFromReader(http.StatusOK, 10, "text/plain", bytes.NewReader([]byte("0123456789")), map[string]string{"X-Extra": "1"})
+	})
+	r.GET("/stream", func(c *Context) {
+		c.DataFromReader(http.StatusOK, 4, "text/plain", bytes.NewBufferString("data"), nil)
+	})
+
+	req := httptest.NewRequest("GET", "/seekable", nil)
+	req.Header.Set("Range", "bytes=-3")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusPartialContent || w.Body.String() != "789" || w.Header().Get("X-Extra") != "1" {
+		t.Fatalf("unexpected seekable response %d %q %v", w.Code, w.Body.String(), w.Header())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/stream", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "data" || w.Header().Get("Content-Length") != "4" {
+		t.Fatalf("unexpected stream response %d %q %v", w.Code, w.Body.String(), w.Header())
+	}
+}
+
+func TestDataFromReaderHeadersAndLength(t *testing.T) {
+	r := New()
+	r.Use(func(c *Context) {
+		c.SetHeader("Cache-Control", "no-store")
+		c.Next()
+	})
+	extra := map[string]string{"Cache-Control": "max-age=60", "X-Extra": "1"}
+	r.GET("/seekable", func(c *Context) {
+		reader := strings.NewReader("header:0123456789")
+		reader.Seek(7, io.SeekStart)
+		c.DataFromReader(http.StatusOK, 5, "text/plain", reader, extra)
+	})
+	r.GET("/stream", func(c *Context) {
+		c.DataFromReader(http.StatusOK, 5, "text/plain", bytes.NewBufferString("01234"), extra)
+	})
+	// 两种 reader 对 extraHeaders 的处理相同，都不覆盖中间件设置的 Header
+	for _, path := range []string{"/seekable", "/stream"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Header().Get("Cache-Control") != "no-store" || w.Header().Get("X-Extra") != "1" {
+			t.Errorf("%s: unexpected headers %v", path, w.Header())
+		}
+		if w.Body.String() != "01234" || w.Header().Get("Content-Length") != "5" {
+			t.Errorf("%s: contentLength should be honoured, got %q %v", path, w.Body.String(), w.Header())
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/seekable", nil)
+	req.Header.Set("Range", "bytes=-2")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusPartialContent || w.Body.String() != "34" || w.Header().Get("Content-Range") != "bytes 3-4/5" {
+		t.Fatalf("range should apply within contentLength: %d %q %v", w.Code, w.Body.String(), w.Header())
+	}
+}
@@ -0,0 +1,357 @@
+package jwt
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+)
+
+/*
+jwt 实现 JSON Web Token(RFC 7519)的签发和校验，只依赖标准库。
+支持的签名算法：HS256、RS256、ES256 和 EdDSA(Ed25519)。
+
+签发：
+
+	key := &jwt.Key{ID: "2024-01", Algorithm: jwt.HS256, Key: []byte("secret")}
+	token, err := jwt.Sign(jwt.NewClaims("amadeus", time.Hour), key)
+
+校验见 Verifier 和 New 中间件。
+*/
+
+// 支持的签名算法
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	ES256 = "ES256"
+	EdDSA = "EdDSA"
+)
+
+var (
+	ErrTokenMalformed        = errors.New("jwt: token is malformed")
+	ErrUnsupportedAlgorithm  = errors.New("jwt: unsupported signing algorithm")
+	ErrKeyNotFound           = errors.New("jwt: no key found for token")
+	ErrSignatureInvalid      = errors.New("jwt: signature is invalid")
+	ErrTokenExpired          = errors.New("jwt: token is expired")
+	ErrTokenNotValidYet      = errors.New("jwt: token is not valid yet")
+	ErrTokenUsedBeforeIssued = errors.New("jwt: token used before issued")
+	ErrInvalidIssuer         = errors.New("jwt: token has invalid issuer")
+	ErrInvalidAudience       = errors.New("jwt: token has invalid audience")
+)
+
+// header JWT 的头部
+type header struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ,omitempty"`
+	KeyID     string `json:"kid,omitempty"`
+}
+
+// Claims JWT 的载荷，标准声明通过对应的方法读取，自定义声明直接按 key 读取
+type Claims map[string]interface{}
+
+// NewClaims 创建有效期为 ttl 的 Claims，包含 sub、iat 和 exp
+func NewClaims(subject string, ttl time.Duration) Claims {
+	now := time.Now()
+	return Claims{
+		"sub": subject,
+		"iat": now.Unix(),
+		"exp": now.Add(ttl).Unix(),
+	}
+}
+
+// Subject 返回 sub 声明
+func (c Claims) Subject() string {
+	s, _ := c["sub"].(string)
+	return s
+}
+
+// Issuer 返回 iss 声明
+func (c Claims) Issuer() string {
+	s, _ := c["iss"].(string)
+	return s
+}
+
+// Audience 返回 aud 声明，aud 可以是字符串或者字符串数组
+func (c Claims) Audience() []string {
+	switch aud := c["aud"].(type) {
+	case string:
+		return []string{aud}
+	case []string:
+		return aud
+	case []interface{}:
+		var auds []string
+		for _, v := range aud {
+			if s, ok := v.(string); ok {
+				auds = append(auds, s)
+			}
+		}
+		return auds
+	}
+	return nil
+}
+
+// ExpiresAt 返回 exp 声明，第二个返回值表示是否存在
+func (c Claims) ExpiresAt() (time.Time, bool) {
+	return c.time("exp")
+}
+
+// NotBefore 返回 nbf 声明
+func (c Claims) NotBefore() (time.Time, bool) {
+	return c.time("nbf")
+}
+
+// IssuedAt 返回 iat 声明
+func (c Claims) IssuedAt() (time.Time, bool) {
+	return c.time("iat")
+}
+
+// time 读取 NumericDate 类型的声明，即从 1970-01-01 开始的秒数
+// 秒数超出 int64 的范围或者是 NaN/Inf 时视为格式错误
+func (c Claims) time(name string) (time.Time, bool) {
+	var seconds float64
+	switch v := c[name].(type) {
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return time.Time{}, false
+		}
+		seconds = f
+	case float64:
+		seconds = v
+	case int64:
+		seconds = float64(v)
+	case int:
+		seconds = float64(v)
+	case time.Time:
+		return v, true
+	default:
+		return time.Time{}, false
+	}
+	// 换算成纳秒会在 2262 年之后溢出，整数和小数部分分开传给 time.Unix
+	if math.IsNaN(seconds) || math.IsInf(seconds, 0) || seconds < math.MinInt64 || seconds >= math.MaxInt64 {
+		return time.Time{}, false
+	}
+	sec, frac := math.Modf(seconds)
+	return time.Unix(int64(sec), int64(frac*1e9)), true
+}
+
+// Sign 使用 key 签发 token，key.ID 不为空时写入头部的 kid
+func Sign(claims Claims, key *Key) (string, error) {
+	alg := key.algorithm()
+	h, err := json.Marshal(header{Algorithm: alg, Type: "JWT", KeyID: key.ID})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signingInput := encodeSegment(h) + "." + encodeSegment(payload)
+	sig, err := sign(alg, key.Key, signingInput)
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + encodeSegment(sig), nil
+}
+
+// sign 按照 alg 计算签名
+func sign(alg string, key interface{}, signingInput string) ([]byte, error) {
+	digest := sha256.Sum256([]byte(signingInput))
+	switch alg {
+	case HS256:
+		secret, ok := key.([]byte)
+		if !ok {
+			return nil, ErrUnsupportedAlgorithm
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(signingInput))
+		return mac.Sum(nil), nil
+	case RS256:
+		priv, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, ErrUnsupportedAlgorithm
+		}
+		return rsa.SignPKCS1v15(rand.Reader, priv, crypto.SHA256, digest[:])
+	case ES256:
+		priv, ok := key.(*ecdsa.PrivateKey)
+		if !ok || priv.Curve.Params().BitSize != 256 {
+			return nil, ErrUnsupportedAlgorithm
+		}
+		r, s, err := ecdsa.Sign(rand.Reader, priv, digest[:])
+		if err != nil {
+			return nil, err
+		}
+		// JWS 中 ES256 的签名是定长的 r||s，各 32 字节
+		sig := make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+		return sig, nil
+	case EdDSA:
+		priv, ok := key.(ed25519.PrivateKey)
+		if !ok {
+			return nil, ErrUnsupportedAlgorithm
+		}
+		return ed25519.Sign(priv, []byte(signingInput)), nil
+	}
+	return nil, ErrUnsupportedAlgorithm
+}
+
+// verify 按照 alg 校验签名，key 可以是公钥，也可以是私钥
+func verify(alg string, key interface{}, signingInput string, sig []byte) bool {
+	digest := sha256.Sum256([]byte(signingInput))
+	switch alg {
+	case HS256:
+		secret, ok := key.([]byte)
+		if !ok {
+			return false
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(signingInput))
+		return hmac.Equal(sig, mac.Sum(nil))
+	case RS256:
+		pub, ok := publicKey(key).(*rsa.PublicKey)
+		return ok && rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig) == nil
+	case ES256:
+		pub, ok := publicKey(key).(*ecdsa.PublicKey)
+		if !ok || len(sig) != 64 || pub.Curve.Params().BitSize != 256 {
+			return false
+		}
+		r := new(big.Int).SetBytes(sig[:32])
+		s := new(big.Int).SetBytes(sig[32:])
+		return ecdsa.Verify(pub, digest[:], r, s)
+	case EdDSA:
+		pub, ok := publicKey(key).(ed25519.PublicKey)
+		return ok && len(pub) == ed25519.PublicKeySize && ed25519.Verify(pub, []byte(signingInput), sig)
+	}
+	return false
+}
+
+// publicKey 私钥转换为对应的公钥，其他类型原样返回
+func publicKey(key interface{}) interface{} {
+	if priv, ok := key.(crypto.Signer); ok {
+		return priv.Public()
+	}
+	return key
+}
+
+// Verifier 校验 token 的签名和标准声明
+type Verifier struct {
+	// Keys 校验签名使用的密钥
+	Keys *KeySet
+	// Issuer 不为空时要求 iss 等于 Issuer
+	Issuer string
+	// Audience 不为空时要求 aud 包含 Audience
+	Audience string
+	// Leeway 校验 exp、nbf、iat 时允许的时钟误差
+	Leeway time.Duration
+	// Now 返回当前时间，默认为 time.Now
+	Now func() time.Time
+}
+
+// Verify 校验 token 并返回其中的声明
+func (v *Verifier) Verify(token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrTokenMalformed
+	}
+	var h header
+	if err := decodeJSON(parts[0], &h); err != nil {
+		return nil, ErrTokenMalformed
+	}
+	sig, err := decodeSegment(parts[2])
+	if err != nil {
+		return nil, ErrTokenMalformed
+	}
+	// 算法由校验方的密钥决定，头部的 alg 只用来挑选密钥，拒绝 none 以及与密钥不符的算法
+	keys, err := v.Keys.lookup(h.KeyID, h.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	signingInput := parts[0] + "." + parts[1]
+	valid := false
+	for _, key := range keys {
+		if verify(h.Algorithm, key.Key, signingInput, sig) {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return nil, ErrSignatureInvalid
+	}
+	var claims Claims
+	if err := decodeJSON(parts[1], &claims); err != nil {
+		return nil, ErrTokenMalformed
+	}
+	if err := v.validate(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// validate 校验 exp、nbf、iat、iss 和 aud
+func (v *Verifier) validate(claims Claims) error {
+	now := time.Now()
+	if v.Now != nil {
+		now = v.Now()
+	}
+	// 时间声明存在但不是数字时不能当作没有，否则 "exp": "never" 这样的 token 永远不会过期
+	for _, name := range []string{"exp", "nbf", "iat"} {
+		if _, present := claims[name]; present {
+			if _, ok := claims.time(name); !ok {
+				return ErrTokenMalformed
+			}
+		}
+	}
+	if exp, ok := claims.ExpiresAt(); ok && !now.Before(exp.Add(v.Leeway)) {
+		return ErrTokenExpired
+	}
+	if nbf, ok := claims.NotBefore(); ok && now.Add(v.Leeway).Before(nbf) {
+		return ErrTokenNotValidYet
+	}
+	if iat, ok := claims.IssuedAt(); ok && now.Add(v.Leeway).Before(iat) {
+		return ErrTokenUsedBeforeIssued
+	}
+	if v.Issuer != "" && claims.Issuer() != v.Issuer {
+		return ErrInvalidIssuer
+	}
+	if v.Audience != "" {
+		for _, aud := range claims.Audience() {
+			if aud == v.Audience {
+				return nil
+			}
+		}
+		return ErrInvalidAudience
+	}
+	return nil
+}
+
+// encodeSegment base64url 编码，不带填充
+func encodeSegment(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeSegment(seg string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(seg)
+}
+
+// decodeJSON 解码 base64url 编码的 JSON，数字保存为 json.Number，避免大整数丢失精度
+func decodeJSON(seg string, v interface{}) error {
+	data, err := decodeSegment(seg)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
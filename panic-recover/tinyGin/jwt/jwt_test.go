@@ -0,0 +1,240 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"tinyGin"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	keys := []*Key{
+		{ID: "hs", Key: []byte("secret")},
+		{ID: "rs", Key: rsaKey},
+		{ID: "es", Key: ecKey},
+		{ID: "ed", Key: edKey},
+	}
+	for _, key := range keys {
+		token, err := Sign(NewClaims("amadeus", time.Hour), key)
+		if err != nil {
+			t.Fatalf("%s: %v", key.ID, err)
+		}
+		// 校验方只持有公钥
+		v := &Verifier{Keys: NewKeySet(&Key{ID: key.ID, Key: publicKey(key.Key)})}
+		claims, err := v.Verify(token)
+		if err != nil || claims.Subject() != "amadeus" {
+			t.Fatalf("%s: got %v %v", key.ID, claims, err)
+		}
+		// 篡改载荷之后签名失效
+		parts := strings.Split(token, ".")
+		parts[1] = encodeSegment([]byte(`{"sub":"root"}`))
+		if _, err := v.Verify(strings.Join(parts, ".")); !errors.Is(err, ErrSignatureInvalid) {
+			t.Fatalf("%s: tampered token should be rejected, got %v", key.ID, err)
+		}
+	}
+}
+
+func TestVerifyRejectsAlgorithmConfusion(t *testing.T) {
+	v := &Verifier{Keys: NewKeySet(&Key{Key: []byte("secret")})}
+	none := encodeSegment([]byte(`{"alg":"none"}`)) + "." + encodeSegment([]byte(`{"sub":"root"}`)) + "."
+	if _, err := v.Verify(none); !errors.Is(err, ErrUnsupportedAlgorithm) {
+		t.Fatalf("alg none should be rejected, got %v", err)
+	}
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	token, _ := Sign(NewClaims("root", time.Hour), &Key{Key: edKey})
+	if _, err := v.Verify(token); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("token signed with another algorithm should be rejected, got %v", err)
+	}
+	if _, err := v.Verify("abc"); !errors.Is(err, ErrTokenMalformed) {
+		t.Fatalf("expected malformed token, got %v", err)
+	}
+}
+
+func TestValidateClaims(t *testing.T) {
+	key := &Key{Key: []byte("secret")}
+	now := time.Unix(1700000000, 0)
+	v := &Verifier{
+		Keys:     NewKeySet(key),
+		Issuer:   "https://auth.example.com",
+		Audience: "api",
+		Leeway:   30 * time.Second,
+		Now:      func() time.Time { return now },
+	}
+	base := func() Claims {
+		return Claims{"sub": "amadeus", "iss": "https://auth.example.com", "aud": []string{"web", "api"}, "exp": now.Unix() + 60}
+	}
+	cases := []struct {
+		name   string
+		modify func(Claims)
+		err    error
+	}{
+		{"valid", func(Claims) {}, nil},
+		{"expired within leeway", func(c Claims) { c["exp"] = now.Unix() - 10 }, nil},
+		{"expired", func(c Claims) { c["exp"] = now.Unix() - 60 }, ErrTokenExpired},
+		{"nbf within leeway", func(c Claims) { c["nbf"] = now.Unix() + 10 }, nil},
+		{"not valid yet", func(c Claims) { c["nbf"] = now.Unix() + 60 }, ErrTokenNotValidYet},
+		{"issued in future", func(c Claims) { c["iat"] = now.Unix() + 60 }, ErrTokenUsedBeforeIssued},
+		{"wrong issuer", func(c Claims) { c["iss"] = "evil" }, ErrInvalidIssuer},
+		{"string audience", func(c Claims) { c["aud"] = "api" }, nil},
+		{"wrong audience", func(c Claims) { c["aud"] = "web" }, ErrInvalidAudience},
+		{"exp is not a number", func(c Claims) { c["exp"] = "never" }, ErrTokenMalformed},
+		{"nbf is not a number", func(c Claims) { c["nbf"] = true }, ErrTokenMalformed},
+		{"iat is null", func(c Claims) { c["iat"] = nil }, ErrTokenMalformed},
+		{"exp in year 9999", func(c Claims) { c["exp"] = 253402300799 }, nil},
+		{"fractional exp", func(c Claims) { c["exp"] = float64(now.Unix()) + 0.5 }, nil},
+		{"exp out of range", func(c Claims) { c["exp"] = 1e300 }, ErrTokenMalformed},
+		{"nbf out of range", func(c Claims) { c["nbf"] = -1e300 }, ErrTokenMalformed},
+	}
+	for _, tc := range cases {
+		claims := base()
+		tc.modify(claims)
+		token, _ := Sign(claims, key)
+		if _, err := v.Verify(token); !errors.Is(err, tc.err) {
+			t.Errorf("%s: got %v, want %v", tc.name, err, tc.err)
+		}
+	}
+}
+
+func TestKeySetRejectsEmptySecret(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("empty HS256 secret should be rejected")
+		}
+	}()
+	NewKeySet(&Key{ID: "empty", Key: []byte{}})
+}
+
+func TestKeySetRejectsMismatchedAlgorithm(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	for _, key := range []*Key{
+		{ID: "rsa", Algorithm: RS256, Key: []byte("secret")},
+		{ID: "hmac", Algorithm: HS256, Key: rsaKey},
+		{ID: "ec", Algorithm: ES256, Key: &rsaKey.PublicKey},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: %s with %T should be rejected", key.ID, key.Algorithm, key.Key)
+				}
+			}()
+			NewKeySet(key)
+		}()
+	}
+	// 算法与类型一致时正常使用
+	NewKeySet(&Key{Algorithm: RS256, Key: rsaKey}, &Key{Algorithm: HS256, Key: []byte("secret")})
+}
+
+// writeJWKS 把公钥写成 JWKS 文件
+func writeJWKS(t *testing.T, filename string, keys map[string]interface{}) {
+	t.Helper()
+	var set []map[string]string
+	for kid, key := range keys {
+		switch pub := key.(type) {
+		case *rsa.PublicKey:
+			set = append(set, map[string]string{"kty": "RSA", "kid": kid, "n": encodeSegment(pub.N.Bytes()),
+				"e": encodeSegment(big.NewInt(int64(pub.E)).Bytes())})
+		case *ecdsa.PublicKey:
+			set = append(set, map[string]string{"kty": "EC", "kid": kid, "crv": "P-256",
+				"x": encodeSegment(pub.X.FillBytes(make([]byte, 32))), "y": encodeSegment(pub.Y.FillBytes(make([]byte, 32)))})
+		case ed25519.PublicKey:
+			set = append(set, map[string]string{"kty": "OKP", "kid": kid, "crv": "Ed25519", "x": encodeSegment(pub)})
+		}
+	}
+	data, _ := json.Marshal(map[string]interface{}{"keys": set})
+	if err := os.WriteFile(filename, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestJWKSRotation(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	filename := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, filename, map[string]interface{}{"2024-01": rsaKey.Public(), "ec": ecKey.Public()})
+
+	keys, err := LoadJWKS(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := &Verifier{Keys: keys}
+	oldToken, _ := Sign(NewClaims("amadeus", time.Hour), &Key{ID: "2024-01", Key: rsaKey})
+	ecToken, _ := Sign(NewClaims("amadeus", time.Hour), &Key{ID: "ec", Key: ecKey})
+	newToken, _ := Sign(NewClaims("amadeus", time.Hour), &Key{ID: "2024-02", Key: edKey})
+	for _, token := range []string{oldToken, ecToken} {
+		if _, err := v.Verify(token); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := v.Verify(newToken); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("unknown kid should be rejected, got %v", err)
+	}
+
+	// 轮换：新密钥加入，旧密钥移除
+	writeJWKS(t, filename, map[string]interface{}{"2024-02": edKey.Public()})
+	if err := keys.LoadJWKS(filename); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := v.Verify(newToken); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := v.Verify(oldToken); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("rotated key should be rejected, got %v", err)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	key := &Key{ID: "k1", Key: []byte("secret")}
+	r := tinyGin.New()
+	r.Use(New(Config{Verifier: Verifier{Keys: NewKeySet(key)}, Cookie: "token"}))
+	r.GET("/me", func(c *tinyGin.Context) {
+		c.String(http.StatusOK, "%s %v", c.MustGet(tinyGin.AuthUserKey), GetClaims(c)["role"])
+	})
+	claims := NewClaims("amadeus", time.Hour)
+	claims["role"] = "admin"
+	token, _ := Sign(claims, key)
+	expired, _ := Sign(NewClaims("amadeus", -time.Minute), key)
+
+	header := httptest.NewRequest("GET", "/me", nil)
+	header.Header.Set("Authorization", "Bearer "+token)
+	cookie := httptest.NewRequest("GET", "/me", nil)
+	cookie.AddCookie(&http.Cookie{Name: "token", Value: token})
+	stale := httptest.NewRequest("GET", "/me", nil)
+	stale.Header.Set("Authorization", "Bearer "+expired)
+	missing := httptest.NewRequest("GET", "/me", nil)
+
+	for i, tc := range []struct {
+		req       *http.Request
+		status    int
+		challenge string
+	}{
+		{header, http.StatusOK, ""},
+		{cookie, http.StatusOK, ""},
+		{stale, http.StatusUnauthorized, `Bearer error="invalid_token", error_description="token is expired"`},
+		{missing, http.StatusUnauthorized, "Bearer"},
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, tc.req)
+		if w.Code != tc.status || w.Header().Get("WWW-Authenticate") != tc.challenge {
+			t.Errorf("case %d: got %d %q", i, w.Code, w.Header().Get("WWW-Authenticate"))
+		}
+		if tc.status == http.StatusOK && w.Body.String() != "amadeus admin" {
+			t.Errorf("case %d: unexpected body %q", i, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package jwt
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"sync"
+)
+
+/*
+密钥和密钥集合。
+轮换密钥时，先把新密钥加入 KeySet，用新密钥签发 token，等旧 token 都过期之后再移除旧密钥；
+token 头部的 kid 用于挑选密钥，没有 kid 时依次尝试所有算法匹配的密钥。
+公钥也可以从本地的 JWKS(RFC 7517)文件加载，文件更新后调用 LoadJWKS 重新加载即可完成轮换。
+*/
+
+// Key 签名或者校验使用的密钥
+// Key 字段的类型由算法决定：HS256 为 []byte，RS256 为 *rsa.PrivateKey/*rsa.PublicKey，
+// ES256 为 *ecdsa.PrivateKey/*ecdsa.PublicKey(P-256)，EdDSA 为 ed25519.PrivateKey/ed25519.PublicKey；
+// 私钥可以同时用于签发和校验
+type Key struct {
+	// ID 写入 token 头部的 kid
+	ID string
+	// Algorithm 签名算法，为空时根据 Key 的类型推断
+	Algorithm string
+	Key       interface{}
+}
+
+// algorithm 返回密钥的签名算法
+func (k *Key) algorithm() string {
+	if k.Algorithm != "" {
+		return k.Algorithm
+	}
+	return keyAlgorithm(k.Key)
+}
+
+// keyAlgorithm 根据密钥的类型推断签名算法，不支持的类型返回空字符串
+func keyAlgorithm(k interface{}) string {
+	switch key := publicKey(k).(type) {
+	case []byte:
+		return HS256
+	case *rsa.PublicKey:
+		return RS256
+	case *ecdsa.PublicKey:
+		if key.Curve == elliptic.P256() {
+			return ES256
+		}
+	case ed25519.PublicKey:
+		return EdDSA
+	}
+	return ""
+}
+
+// KeySet 校验 token 时使用的一组密钥，可以安全地被多个协程同时使用
+type KeySet struct {
+	mu   sync.RWMutex
+	keys []*Key
+}
+
+// NewKeySet 创建包含 keys 的 KeySet，密钥无效(例如 HS256 的密钥为空)时 panic
+func NewKeySet(keys ...*Key) *KeySet {
+	s := &KeySet{}
+	s.Replace(keys...)
+	return s
+}
+
+// Add 添加密钥，已存在相同 ID 的密钥时替换，密钥无效时 panic
+func (s *KeySet) Add(key *Key) {
+	mustValidKey(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, k := range s.keys {
+		if key.ID != "" && k.ID == key.ID {
+			s.keys[i] = key
+			return
+		}
+	}
+	s.keys = append(s.keys, key)
+}
+
+// Remove 移除 ID 为 kid 的密钥
+func (s *KeySet) Remove(kid string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := s.keys[:0]
+	for _, k := range s.keys {
+		if k.ID != kid {
+			keys = append(keys, k)
+		}
+	}
+	s.keys = keys
+}
+
+// Replace 用 keys 替换全部密钥，密钥无效时 panic
+func (s *KeySet) Replace(keys ...*Key) {
+	for _, key := range keys {
+		mustValidKey(key)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = append([]*Key(nil), keys...)
+}
+
+// mustValidKey 检查密钥，空的 HS256 密钥任何人都能用来伪造 token
+// 显式指定的 Algorithm 必须与 Key 的类型一致，否则这个密钥永远无法校验通过，例如 RS256 配上 []byte
+func mustValidKey(key *Key) {
+	if key == nil {
+		panic("jwt: nil key")
+	}
+	if secret, ok := key.Key.([]byte); ok && len(secret) == 0 {
+		panic("jwt: HS256 key " + strconv.Quote(key.ID) + " has an empty secret")
+	}
+	if key.Algorithm != "" && key.Algorithm != keyAlgorithm(key.Key) {
+		panic(fmt.Sprintf("jwt: key %q is %T, which cannot be used with %s", key.ID, key.Key, key.Algorithm))
+	}
+}
+
+// lookup 返回可以校验 token 的密钥
+// kid 不为空时只使用 ID 相同的密钥，密钥的算法必须与 alg 一致
+func (s *KeySet) lookup(kid, alg string) ([]*Key, error) {
+	switch alg {
+	case HS256, RS256, ES256, EdDSA:
+	default:
+		return nil, ErrUnsupportedAlgorithm
+	}
+	if s == nil {
+		return nil, ErrKeyNotFound
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var keys []*Key
+	for _, k := range s.keys {
+		if (kid == "" || k.ID == kid) && k.algorithm() == alg {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, ErrKeyNotFound
+	}
+	return keys, nil
+}
+
+// jwk JWKS 文件中的一个密钥，只支持公钥和对称密钥
+type jwk struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	Curve     string `json:"crv"`
+	K         string `json:"k"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+	X         string `json:"x"`
+	Y         string `json:"y"`
+}
+
+// LoadJWKS 从本地的 JWKS 文件创建 KeySet
+func LoadJWKS(filename string) (*KeySet, error) {
+	s := &KeySet{}
+	if err := s.LoadJWKS(filename); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// LoadJWKS 从本地的 JWKS 文件重新加载全部密钥，加载失败时保留原来的密钥
+func (s *KeySet) LoadJWKS(filename string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return fmt.Errorf("jwt: invalid JWKS %s: %w", filename, err)
+	}
+	keys := make([]*Key, 0, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use == "enc" {
+			continue
+		}
+		key, err := k.key()
+		if err != nil {
+			return fmt.Errorf("jwt: invalid key %q in %s: %w", k.KeyID, filename, err)
+		}
+		keys = append(keys, key)
+	}
+	s.Replace(keys...)
+	return nil
+}
+
+// key 将 JWK 转换为 Key
+func (k *jwk) key() (*Key, error) {
+	key := &Key{ID: k.KeyID, Algorithm: k.Algorithm}
+	switch k.KeyType {
+	case "oct":
+		secret, err := decodeSegment(k.K)
+		if err != nil || len(secret) == 0 {
+			return nil, errors.New("invalid k")
+		}
+		key.Key = secret
+	case "RSA":
+		n, err1 := decodeSegment(k.N)
+		e, err2 := decodeSegment(k.E)
+		if err1 != nil || err2 != nil || len(n) == 0 || len(e) == 0 || len(e) > 4 {
+			return nil, errors.New("invalid n or e")
+		}
+		key.Key = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	case "EC":
+		if k.Curve != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Curve)
+		}
+		x, err1 := decodeSegment(k.X)
+		y, err2 := decodeSegment(k.Y)
+		if err1 != nil || err2 != nil || len(x) != 32 || len(y) != 32 {
+			return nil, errors.New("invalid x or y")
+		}
+		// 借助 crypto/ecdh 检查点是否在曲线上
+		if _, err := ecdh.P256().NewPublicKey(append(append([]byte{4}, x...), y...)); err != nil {
+			return nil, err
+		}
+		key.Key = &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+	case "OKP":
+		x, err := decodeSegment(k.X)
+		if k.Curve != "Ed25519" || err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("unsupported curve %q", k.Curve)
+		}
+		key.Key = ed25519.PublicKey(x)
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.KeyType)
+	}
+	alg := keyAlgorithm(key.Key)
+	if alg == "" {
+		return nil, ErrUnsupportedAlgorithm
+	}
+	if key.Algorithm != "" && key.Algorithm != alg {
+		return nil, fmt.Errorf("alg %q does not match key type %q", key.Algorithm, k.KeyType)
+	}
+	return key, nil
+}
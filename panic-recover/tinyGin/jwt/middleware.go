@@ -0,0 +1,74 @@
+package jwt
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"tinyGin"
+)
+
+// ClaimsKey 校验通过后 Claims 在 Context 中保存的 key
+const ClaimsKey = "tinyGin/jwt"
+
+// ErrTokenMissing 请求中没有 token
+var ErrTokenMissing = errors.New("jwt: token is missing")
+
+// Config JWT 中间件的配置
+type Config struct {
+	Verifier
+	// Cookie 不为空时，Authorization 请求头中没有 token 的情况下从这个 Cookie 中读取
+	Cookie string
+}
+
+// New JWT 认证中间件
+// token 从 Authorization: Bearer 请求头或者 Config.Cookie 中读取，校验通过后 Claims 保存在 c.Keys[ClaimsKey] 中，
+// sub 声明保存在 c.Keys[tinyGin.AuthUserKey] 中；校验失败时返回 401，错误记录在 c.Errors 中
+func New(conf Config) tinyGin.HandlerFunc {
+	if conf.Keys == nil {
+		panic("jwt: Config.Keys is required")
+	}
+	return func(c *tinyGin.Context) {
+		token := conf.token(c)
+		if token == "" {
+			c.Error(ErrTokenMissing)
+			c.SetHeader("WWW-Authenticate", "Bearer")
+			c.Fail(http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+		claims, err := conf.Verify(token)
+		if err != nil {
+			c.Error(err)
+			description := strings.TrimPrefix(err.Error(), "jwt: ")
+			c.SetHeader("WWW-Authenticate", `Bearer error="invalid_token", error_description=`+strconv.Quote(description))
+			c.Fail(http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+		c.Set(ClaimsKey, claims)
+		c.Set(tinyGin.AuthUserKey, claims.Subject())
+		c.Next()
+	}
+}
+
+// token 从 Authorization 请求头或者 Cookie 中读取 token
+func (conf *Config) token(c *tinyGin.Context) string {
+	scheme, token, _ := strings.Cut(c.Req.Header.Get("Authorization"), " ")
+	if strings.EqualFold(scheme, "Bearer") && strings.TrimSpace(token) != "" {
+		return strings.TrimSpace(token)
+	}
+	if conf.Cookie != "" {
+		if token, err := c.Cookie(conf.Cookie); err == nil {
+			return token
+		}
+	}
+	return ""
+}
+
+// GetClaims 返回 New 中间件保存的 Claims，没有经过 JWT 认证时返回 nil
+func GetClaims(c *tinyGin.Context) Claims {
+	if v, ok := c.Get(ClaimsKey); ok {
+		claims, _ := v.(Claims)
+		return claims
+	}
+	return nil
+}
@@ -1,17 +1,268 @@
 package tinyGin
 
 import (
-	"log"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
 	"time"
 )
 
+/*
+Logger 中间件在请求处理完成后输出一行访问日志。
+通过 LoggerWithConfig 可以自定义日志格式、输出位置，以及跳过健康检查之类不需要记录的请求。
+输出到终端时，状态码和请求方法会带上 ANSI 颜色，可以通过 Engine.DisableConsoleColor/ForceConsoleColor 控制。
+*/
+
+// 终端颜色
+const (
+	green   = "\033[97;42m"
+	white   = "\033[90;47m"
+	yellow  = "\033[90;43m"
+	red     = "\033[97;41m"
+	blue    = "\033[97;44m"
+	magenta = "\033[97;45m"
+	cyan    = "\033[97;46m"
+	reset   = "\033[0m"
+)
+
+// consoleColorMode 日志是否带颜色
+type consoleColorMode int
+
+const (
+	autoColor    consoleColorMode = iota // 输出是终端时带颜色
+	disableColor                         // 不带颜色
+	forceColor                           // 总是带颜色
+)
+
+// LoggerConfig Logger 中间件的配置
+type LoggerConfig struct {
+	// Formatter 日志格式，默认为 defaultLogFormatter
+	Formatter LogFormatter
+	// Output 日志输出的位置，默认为 os.Stderr
+	Output io.Writer
+	// SkipPaths 不记录日志的请求路径
+	SkipPaths []string
+	// Skip 返回 true 时不记录日志
+	Skip func(c *Context) bool
+}
+
+// LogFormatter 将一次请求的信息格式化成一行日志
+type LogFormatter func(params LogFormatterParams) string
+
+// LogFormatterParams 传给 LogFormatter 的请求信息
+type LogFormatterParams struct {
+	Request *http.Request
+
+	// TimeStamp 请求处理完成的时间
+	TimeStamp time.Time
+	// StatusCode 响应状态码
+	StatusCode int
+	// Latency 处理请求花费的时间
+	Latency time.Duration
+	// ClientIP 客户端的真实 IP，见 Context.ClientIP
+	ClientIP string
+	// Method 请求方法
+	Method string
+	// Path 请求路径，包含 Query 参数
+	Path string
+	// ErrorMessage 处理过程中通过 c.Error 记录的内部错误
+	ErrorMessage string
+	// BodySize 响应体的字节数
+	BodySize int
+	// Keys 通过 c.Set 保存的数据
+	Keys map[string]interface{}
+
+	// isTerm 是否输出颜色
+	isTerm bool
+}
+
+// StatusCodeColor 状态码对应的颜色
+func (p *LogFormatterParams) StatusCodeColor() string {
+	code := p.StatusCode
+	switch {
+	case code >= http.StatusContinue && code < http.StatusOK:
+		return white
+	case code >= http.StatusOK && code < http.StatusMultipleChoices:
+		return green
+	case code >= http.StatusMultipleChoices && code < http.StatusBadRequest:
+		return white
+	case code >= http.StatusBadRequest && code < http.StatusInternalServerError:
+		return yellow
+	default:
+		return red
+	}
+}
+
+// MethodColor 请求方法对应的颜色
+func (p *LogFormatterParams) MethodColor() string {
+	switch p.Method {
+	case http.MethodGet:
+		return blue
+	case http.MethodPost:
+		return cyan
+	case http.MethodPut:
+		return yellow
+	case http.MethodDelete:
+		return red
+	case http.MethodPatch:
+		return green
+	case http.MethodHead:
+		return magenta
+	case http.MethodOptions:
+		return white
+	default:
+		return reset
+	}
+}
+
+// ResetColor 重置颜色
+func (p *LogFormatterParams) ResetColor() string {
+	return reset
+}
+
+// IsOutputColor 是否输出颜色
+func (p *LogFormatterParams) IsOutputColor() bool {
+	return p.isTerm
+}
+
+// defaultLogFormatter 默认的日志格式
+var defaultLogFormatter = func(param LogFormatterParams) string {
+	var statusColor, methodColor, resetColor string
+	if param.IsOutputColor() {
+		statusColor = param.StatusCodeColor()
+		methodColor = param.MethodColor()
+		resetColor = param.ResetColor()
+	}
+	if param.Latency > time.Minute {
+		param.Latency = param.Latency.Truncate(time.Second)
+	}
+	return fmt.Sprintf("[tinyGin] %v |%s %3d %s| %13v | %15s |%s %-7s %s %#v\n%s",
+		param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+		statusColor, param.StatusCode, resetColor,
+		param.Latency,
+		param.ClientIP,
+		methodColor, param.Method, resetColor,
+		param.Path,
+		param.ErrorMessage,
+	)
+}
+
+// Logger 使用默认配置的日志中间件
 func Logger() HandlerFunc {
+	return LoggerWithConfig(LoggerConfig{})
+}
+
+// LoggerWithFormatter 使用自定义格式的日志中间件
+func LoggerWithFormatter(f LogFormatter) HandlerFunc {
+	return LoggerWithConfig(LoggerConfig{Formatter: f})
+}
+
+// LoggerWithWriter 输出到 out 的日志中间件，notLogged 中的路径不会记录
+func LoggerWithWriter(out io.Writer, notLogged ...string) HandlerFunc {
+	return LoggerWithConfig(LoggerConfig{Output: out, SkipPaths: notLogged})
+}
+
+// LoggerWithConfig 按照 conf 创建日志中间件
+func LoggerWithConfig(conf LoggerConfig) HandlerFunc {
+	formatter := conf.Formatter
+	if formatter == nil {
+		formatter = defaultLogFormatter
+	}
+	out := conf.Output
+	if out == nil {
+		out = os.Stderr
+	}
+	isTerm := isTerminal(out)
+
+	var skip map[string]struct{}
+	if length := len(conf.SkipPaths); length > 0 {
+		skip = make(map[string]struct{}, length)
+		for _, path := range conf.SkipPaths {
+			skip[path] = struct{}{}
+		}
+	}
+
 	return func(c *Context) {
 		// Start timer
-		t := time.Now()
+		start := time.Now()
+		path := c.Req.URL.Path
+		raw := c.Req.URL.RawQuery
+
 		// Process request   调用后续处理函数
 		c.Next()
+
+		if _, ok := skip[path]; ok || (conf.Skip != nil && conf.Skip(c)) {
+			return
+		}
+		param := LogFormatterParams{
+			Request: c.Req,
+			isTerm:  c.engine.useConsoleColor(isTerm),
+			Keys:    c.copyKeys(),
+		}
 		// Calculate resolution time
-		log.Printf("[%d] %s in %v", c.StatusCode, c.Req.RequestURI, time.Since(t))
+		param.TimeStamp = time.Now()
+		param.Latency = param.TimeStamp.Sub(start)
+		param.ClientIP = c.ClientIP()
+		param.Method = c.Req.Method
+		param.StatusCode = c.Writer.Status()
+		param.ErrorMessage = c.Errors.ByType(ErrorTypePrivate).String()
+		param.BodySize = c.Writer.Size()
+		if raw != "" {
+			// token、api_key 等敏感参数的值不能出现在日志中
+			path = path + "?" + maskQuery(raw)
+		}
+		param.Path = path
+
+		fmt.Fprint(out, formatter(param))
+	}
+}
+
+// copyKeys 复制 c.Keys，日志格式化时不会受到其他协程修改的影响
+func (c *Context) copyKeys() map[string]interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.Keys == nil {
+		return nil
+	}
+	keys := make(map[string]interface{}, len(c.Keys))
+	for k, v := range c.Keys {
+		keys[k] = v
+	}
+	return keys
+}
+
+// isTerminal 判断 w 是否是终端，只有输出到终端时才默认带颜色
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// DisableConsoleColor 关闭日志颜色
+func (e *Engine) DisableConsoleColor() {
+	e.consoleColor = disableColor
+}
+
+// ForceConsoleColor 总是输出日志颜色，即使输出的不是终端
+func (e *Engine) ForceConsoleColor() {
+	e.consoleColor = forceColor
+}
+
+// useConsoleColor 根据 Engine 的设置和输出是否是终端，决定日志是否带颜色
+func (e *Engine) useConsoleColor(isTerm bool) bool {
+	mode := autoColor
+	if e != nil {
+		mode = e.consoleColor
+	}
+	switch mode {
+	case forceColor:
+		return true
+	case disableColor:
+		return false
 	}
+	return isTerm
 }
@@ -0,0 +1,62 @@
+package tinyGin
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLoggerWithConfig(t *testing.T) {
+	var buf bytes.Buffer
+	var params []LogFormatterParams
+	r := New()
+	r.Use(LoggerWithConfig(LoggerConfig{
+		Output: &buf,
+		Formatter: func(p LogFormatterParams) string {
+			params = append(params, p)
+			return p.Method + " " + p.Path + "\n"
+		},
+		SkipPaths: []string{"/health"},
+		Skip: func(c *Context) bool {
+			return c.Query("quiet") != ""
+		},
+	}))
+	r.GET("/health", func(c *Context) {})
+	r.GET("/users", func(c *Context) {
+		c.Set("user", "amadeus")
+		c.Error(errors.New("cache miss"))
+		c.String(http.StatusCreated, "hello")
+	})
+	for _, path := range []string{"/health", "/users?quiet=1", "/users?page=2&token=abc"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+	if buf.String() != "GET /users?page=2&token=*\n" || len(params) != 1 {
+		t.Fatalf("unexpected log output %q", buf.String())
+	}
+	p := params[0]
+	if p.StatusCode != http.StatusCreated || p.BodySize != 5 || p.Keys["user"] != "amadeus" ||
+		!strings.Contains(p.ErrorMessage, "cache miss") || p.ClientIP != "192.0.2.1" {
+		t.Fatalf("unexpected params %+v", p)
+	}
+}
+
+func TestLoggerConsoleColor(t *testing.T) {
+	var buf bytes.Buffer
+	r := New()
+	r.Use(LoggerWithWriter(&buf))
+	r.GET("/", func(c *Context) {})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if strings.Contains(buf.String(), "\033[") {
+		t.Fatalf("non-terminal output should not be colored: %q", buf.String())
+	}
+	buf.Reset()
+	r.ForceConsoleColor()
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if !strings.Contains(buf.String(), green+" 200 "+reset) {
+		t.Fatalf("expected colored status: %q", buf.String())
+	}
+}
@@ -0,0 +1,135 @@
+package tinyGin
+
+import (
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+/*
+文件上传。
+FormFile/MultipartForm 会解析整个 multipart 表单，不超过 Engine.MaxMultipartMemory 的部分保存在内存中，其余的写入临时文件；
+上传大文件时可以使用 EachPart 逐个读取各部分，数据直接从请求体中流式读取，不会先落盘。
+*/
+
+// defaultMultipartMemory Engine.MaxMultipartMemory 的默认值
+const defaultMultipartMemory = 32 << 20 // 32 MB
+
+// ErrUnsafePath 保存上传文件时目标路径试图跳出目录
+var ErrUnsafePath = errors.New("tinyGin: unsafe upload path")
+
+// MultipartForm 解析并返回 multipart 表单，包括普通字段和上传的文件
+func (c *Context) MultipartForm() (*multipart.Form, error) {
+	if c.Req.MultipartForm == nil {
+		if err := c.Req.ParseMultipartForm(c.maxMultipartMemory()); err != nil {
+			return nil, err
+		}
+	}
+	return c.Req.MultipartForm, nil
+}
+
+// FormFile 返回表单中名为 name 的第一个文件
+func (c *Context) FormFile(name string) (*multipart.FileHeader, error) {
+	form, err := c.MultipartForm()
+	if err != nil {
+		return nil, err
+	}
+	if files := form.File[name]; len(files) > 0 {
+		return files[0], nil
+	}
+	return nil, http.ErrMissingFile
+}
+
+func (c *Context) maxMultipartMemory() int64 {
+	if c.engine != nil && c.engine.MaxMultipartMemory > 0 {
+		return c.engine.MaxMultipartMemory
+	}
+	return defaultMultipartMemory
+}
+
+// SaveUploadedFile 将上传的文件保存到 dst
+// dst 是已存在的目录或者以 / 结尾时，文件保存在该目录下，文件名取客户端上传的文件名(去掉其中的路径)
+// 客户端提供的文件名不可信，dst 中包含 .. 时会返回 ErrUnsafePath，防止文件被写到目录之外
+func (c *Context) SaveUploadedFile(file *multipart.FileHeader, dst string) error {
+	target, err := uploadPath(file.Filename, dst)
+	if err != nil {
+		return err
+	}
+	src, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	if err := os.MkdirAll(filepath.Dir(target), 0o750); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, src); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// uploadPath 计算上传文件实际保存的路径
+func uploadPath(filename, dst string) (string, error) {
+	for _, elem := range strings.Split(filepath.ToSlash(dst), "/") {
+		if elem == ".." {
+			return "", ErrUnsafePath
+		}
+	}
+	isDir := strings.HasSuffix(filepath.ToSlash(dst), "/")
+	if info, err := os.Stat(dst); err == nil && info.IsDir() {
+		isDir = true
+	}
+	if !isDir {
+		return filepath.Clean(dst), nil
+	}
+	name := safeFilename(filename)
+	if name == "" {
+		return "", ErrUnsafePath
+	}
+	return filepath.Join(dst, name), nil
+}
+
+// safeFilename 去掉文件名中的路径部分，Windows 风格的 \ 也当作分隔符处理
+func safeFilename(filename string) string {
+	filename = strings.ReplaceAll(filename, "\\", "/")
+	name := filename[strings.LastIndex(filename, "/")+1:]
+	if name == "." || name == ".." {
+		return ""
+	}
+	return name
+}
+
+// EachPart 以流的方式逐个读取 multipart 请求体中的各个部分，不会把文件缓存到内存或磁盘
+// fn 返回错误时停止读取并返回该错误；part 只在 fn 执行期间有效
+// 使用 EachPart 之后就不能再调用 FormFile、MultipartForm 和 PostForm 了
+func (c *Context) EachPart(fn func(part *multipart.Part) error) error {
+	reader, err := c.Req.MultipartReader()
+	if err != nil {
+		return err
+	}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		err = fn(part)
+		part.Close()
+		if err != nil {
+			return err
+		}
+	}
+}
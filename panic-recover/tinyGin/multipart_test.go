@@ -0,0 +1,126 @@
+package tinyGin
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newUploadRequest(t *testing.T, filename, content string) *http.Request {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	mw.WriteField("title", "report")
+	fw, err := mw.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fw.Write([]byte(content))
+	mw.Close()
+	req := httptest.NewRequest("POST", "/upload", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestSaveUploadedFile(t *testing.T) {
+	dir := t.TempDir()
+	r := New()
+	r.POST("/upload", func(c *Context) {
+		file, err := c.FormFile("file")
+		if err != nil {
+			c.Fail(http.StatusBadRequest, err.Error())
+			return
+		}
+		if err := c.SaveUploadedFile(file, dir); err != nil {
+			c.Fail(http.StatusBadRequest, err.Error())
+			return
+		}
+		c.String(http.StatusOK, file.Filename)
+	})
+	w := httptest.NewRecorder()
+	// multipart.Part 只会去掉文件名中的路径，但 FileHeader.Filename 仍然可能带有 ..
+	r.ServeHTTP(w, newUploadRequest(t, "..\\..\\evil.txt", "hello"))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected response %d %s", w.Code, w.Body.String())
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "evil.txt"))
+	if err != nil || string(data) != "hello" {
+		t.Fatalf("file should be saved inside dir: %v %q", err, data)
+	}
+}
+
+func TestUploadPath(t *testing.T) {
+	dir := t.TempDir()
+	cases := []struct {
+		filename, dst, want string
+		err                 error
+	}{
+		{"a.txt", dir, filepath.Join(dir, "a.txt"), nil},
+		{"../../a.txt", dir + "/", filepath.Join(dir, "a.txt"), nil},
+		{"a.txt", filepath.Join(dir, "b.txt"), filepath.Join(dir, "b.txt"), nil},
+		{"a.txt", dir + "/../../etc/passwd", "", ErrUnsafePath},
+		{"..", dir, "", ErrUnsafePath},
+	}
+	for _, tc := range cases {
+		got, err := uploadPath(tc.filename, tc.dst)
+		if got != tc.want || err != tc.err {
+			t.Errorf("uploadPath(%q, %q) = %q, %v", tc.filename, tc.dst, got, err)
+		}
+	}
+}
+
+func TestEachPart(t *testing.T) {
+	req := newUploadRequest(t, "big.bin", "streamed content")
+	c := newContext(httptest.NewRecorder(), req)
+	var names []string
+	var content []byte
+	err := c.EachPart(func(part *multipart.Part) error {
+		names = append(names, part.FormName())
+		if part.FileName() != "" {
+			content, _ = io.ReadAll(part)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 || string(content) != "streamed content" {
+		t.Fatalf("unexpected parts %v %q", names, content)
+	}
+}
+
+func TestFormFileMissing(t *testing.T) {
+	c := newContext(httptest.NewRecorder(), newUploadRequest(t, "a.txt", "hello"))
+	if _, err := c.FormFile("missing"); err != http.ErrMissingFile {
+		t.Fatalf("expected http.ErrMissingFile, got %v", err)
+	}
+}
+
+func TestBindMultipartUsesMaxMultipartMemory(t *testing.T) {
+	r := New()
+	r.MaxMultipartMemory = 1
+	r.POST("/upload", func(c *Context) {
+		var form struct {
+			Title string `form:"title"`
+		}
+		if err := c.ShouldBind(&form); err != nil || form.Title != "report" {
+			t.Errorf("unexpected bind result %v %+v", err, form)
+		}
+		file, _ := c.FormFile("file")
+		f, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		// 超出 MaxMultipartMemory 的文件写入了临时文件
+		if _, ok := f.(*os.File); !ok {
+			t.Errorf("file larger than MaxMultipartMemory should be stored on disk, got %T", f)
+		}
+	})
+	r.ServeHTTP(httptest.NewRecorder(), newUploadRequest(t, "a.txt", strings.Repeat("x", 1024)))
+}
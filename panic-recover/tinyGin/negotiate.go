@@ -0,0 +1,149 @@
+package tinyGin
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 内容协商：同一个接口根据请求头 Accept 返回 JSON、XML、YAML 或者 HTML。
+// Accept 中的每一项可以带有 q 值表示优先级，例如
+//
+//	Accept: text/html, application/xml;q=0.9, */*;q=0.8
+//
+// 服务端提供的每个格式取能匹配它的最具体的一项的 q 值，例如上面的 text/html 的 q 为 1，
+// application/json 只能匹配 */*，q 为 0.8；q=0 表示不接受该格式，q 相同时按服务端的偏好选择。
+
+// Negotiate 内容协商的配置
+// Offered 是服务端能够提供的格式，按服务端的偏好排序；各格式专用的数据为空时使用 Data
+type Negotiate struct {
+	Offered  []string
+	HTMLName string
+	HTMLData interface{}
+	JSONData interface{}
+	XMLData  interface{}
+	YAMLData interface{}
+	Data     interface{}
+}
+
+// Negotiate 根据 Accept 选择响应格式，没有可以满足的格式时返回 406
+// text/plain 以字符串的形式返回 Data，Offered 中的其他格式无法渲染，记录错误并返回 500
+func (c *Context) Negotiate(code int, config Negotiate) {
+	switch format := c.NegotiateFormat(config.Offered...); format {
+	case MIMEJSON:
+		c.Json(code, chooseData(config.JSONData, config.Data))
+	case MIMEHTML:
+		c.HTML(code, config.HTMLName, chooseData(config.HTMLData, config.Data))
+	case MIMEXML, MIMEXML2:
+		c.XML(code, chooseData(config.XMLData, config.Data))
+	case MIMEYAML, MIMEYAML2:
+		c.YAML(code, chooseData(config.YAMLData, config.Data))
+	case MIMEPlain:
+		c.String(code, "%v", config.Data)
+	case "":
+		c.Fail(http.StatusNotAcceptable, "the accepted formats are not offered by the server")
+	default:
+		c.Error(fmt.Errorf("tinyGin: Negotiate cannot render offered format %q", format)).SetType(ErrorTypeRender)
+		c.Fail(http.StatusInternalServerError, "Internal Server Error")
+	}
+}
+
+func chooseData(custom, wildcard interface{}) interface{} {
+	if custom != nil {
+		return custom
+	}
+	return wildcard
+}
+
+// NegotiateFormat 从 offered 中选出客户端最希望得到的格式，没有匹配时返回空字符串
+// 请求没有 Accept 头时，直接返回 offered 中的第一个
+func (c *Context) NegotiateFormat(offered ...string) string {
+	if len(offered) == 0 {
+		panic("tinyGin: you must provide at least one offer")
+	}
+	accepted := parseAccept(c.Req.Header.Get("Accept"))
+	if len(accepted) == 0 {
+		return offered[0]
+	}
+	best, bestQ := "", 0.0
+	for _, offer := range offered {
+		// offered 按服务端的偏好排序，q 相同时保留前面的
+		if q := offerQuality(accepted, offer); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// offerQuality 返回 offer 的 q 值，取能匹配它的最具体的一项，没有匹配时为 0
+// accepted 中 specificity 相同的项按 q 值从大到小排列，所以只在更具体时替换
+func offerQuality(accepted []acceptItem, offer string) float64 {
+	q, level := 0.0, -1
+	for _, accept := range accepted {
+		if l := specificity(accept.mediaType); l > level && matchMediaType(accept.mediaType, offer) {
+			q, level = accept.q, l
+		}
+	}
+	return q
+}
+
+// acceptItem Accept 中的一项
+type acceptItem struct {
+	mediaType string
+	q         float64
+}
+
+// parseAccept 解析 Accept 头，结果按 q 值从大到小排序，q 相同时越具体的类型越靠前
+func parseAccept(header string) []acceptItem {
+	var items []acceptItem
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+		if mediaType == "" {
+			continue
+		}
+		item := acceptItem{mediaType: mediaType, q: 1}
+		for _, param := range params[1:] {
+			key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || strings.ToLower(strings.TrimSpace(key)) != "q" {
+				continue
+			}
+			if q, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil && q >= 0 && q <= 1 {
+				item.q = q
+			}
+		}
+		items = append(items, item)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].q != items[j].q {
+			return items[i].q > items[j].q
+		}
+		return specificity(items[i].mediaType) > specificity(items[j].mediaType)
+	})
+	return items
+}
+
+// specificity 通配所有类型的 */* 最不具体，type/* 其次，完整的类型最具体
+func specificity(mediaType string) int {
+	switch {
+	case mediaType == "*/*" || mediaType == "*":
+		return 0
+	case strings.HasSuffix(mediaType, "/*"):
+		return 1
+	}
+	return 2
+}
+
+// matchMediaType 判断 Accept 中的一项是否能接受服务端提供的 offer，支持 */* 和 type/* 通配符
+func matchMediaType(accept, offer string) bool {
+	offer = strings.ToLower(offer)
+	if accept == "*/*" || accept == "*" || accept == offer {
+		return true
+	}
+	if strings.HasSuffix(accept, "/*") {
+		return strings.HasPrefix(offer, accept[:len(accept)-1])
+	}
+	return false
+}
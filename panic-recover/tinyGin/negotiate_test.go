@@ -0,0 +1,85 @@
+package tinyGin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNegotiateFormat(t *testing.T) {
+	offered := []string{MIMEJSON, MIMEXML, MIMEHTML}
+	cases := []struct {
+		accept string
+		want   string
+	}{
+		{"", MIMEJSON},
+		{"application/xml", MIMEXML},
+		{"text/html, application/xml;q=0.9, */*;q=0.8", MIMEHTML},
+		{"application/json;q=0.5, application/xml", MIMEXML},
+		{"*/*;q=0.1, text/*", MIMEHTML},
+		{"image/png", ""},
+		{"application/json;q=0", ""},
+		{"application/json;q=0, */*", MIMEXML},
+		{"*/*, application/json;q=0.2", MIMEXML},
+		{"text/*;q=0.3, */*;q=0.5, application/xml;q=0.4", MIMEJSON},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest("GET", "/", nil)
+		if tc.accept != "" {
+			req.Header.Set("Accept", tc.accept)
+		}
+		c := newContext(httptest.NewRecorder(), req)
+		if got := c.NegotiateFormat(offered...); got != tc.want {
+			t.Errorf("Accept %q: got %q, want %q", tc.accept, got, tc.want)
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	config := Negotiate{Offered: []string{MIMEJSON, MIMEXML}, Data: H{"a": 1}}
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept", "text/xml, application/xml")
+	newContext(w, req).Negotiate(http.StatusOK, config)
+	if ct := w.Header().Get("Content-Type"); ct != "application/xml; charset=utf-8" {
+		t.Fatalf("unexpected Content-Type %q", ct)
+	}
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept", "text/html")
+	newContext(w, req).Negotiate(http.StatusOK, config)
+	if w.Code != http.StatusNotAcceptable {
+		t.Fatalf("expected 406, got %d", w.Code)
+	}
+
+	// text/plain 没有专门的渲染方式，以字符串的形式返回
+	config = Negotiate{Offered: []string{MIMEPlain, MIMEJSON}, Data: "hello"}
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept", "text/plain")
+	newContext(w, req).Negotiate(http.StatusOK, config)
+	if w.Code != http.StatusOK || w.Body.String() != "hello" {
+		t.Fatalf("unexpected text/plain response %d %q", w.Code, w.Body.String())
+	}
+
+	// 匹配到无法渲染的格式时不是客户端的问题，返回 500 并记录错误
+	config = Negotiate{Offered: []string{"image/png"}, Data: []byte{}}
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept", "image/*")
+	c := newContext(w, req)
+	c.Negotiate(http.StatusOK, config)
+	if w.Code != http.StatusInternalServerError || len(c.Errors.ByType(ErrorTypeRender)) != 1 {
+		t.Fatalf("expected 500 with a render error, got %d %v", w.Code, c.Errors)
+	}
+}
+
+func TestHMarshalXML(t *testing.T) {
+	w := httptest.NewRecorder()
+	newContext(w, httptest.NewRequest("GET", "/", nil)).XML(http.StatusOK, H{"b": 2, "a": "x"})
+	if got := w.Body.String(); got != "<map><a>x</a><b>2</b></map>" {
+		t.Fatalf("unexpected body %q", got)
+	}
+}
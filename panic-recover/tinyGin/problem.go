@@ -0,0 +1,128 @@
+package tinyGin
+
+import (
+	"encoding/json"
+	"net/http"
+	"tinyGin/render"
+)
+
+/*
+RFC 7807 (Problem Details for HTTP APIs) 定义了一种通用的错误响应格式，Content-Type 为 application/problem+json，例如
+	{
+		"type": "about:blank",
+		"title": "Not Found",
+		"status": 404,
+		"detail": "no route for /xxx",
+		"instance": "/xxx"
+	}
+Engine.ProblemDetails 为 true 时，c.Fail、Recovery、404/405 以及参数校验失败都会返回这种格式。
+*/
+
+// MIMEProblemJSON RFC 7807 规定的 Content-Type
+const MIMEProblemJSON = "application/problem+json"
+
+// Problem RFC 7807 中的 problem details 对象
+// Extensions 中的字段会和标准字段平铺在同一层输出
+type Problem struct {
+	Type       string                 // 问题类型的 URI，为空时视为 about:blank
+	Title      string                 // 简短的描述，同一类问题的 Title 应该相同
+	Status     int                    // HTTP 状态码
+	Detail     string                 // 针对这一次请求的具体描述
+	Instance   string                 // 标识这一次问题的 URI，一般是请求路径
+	Extensions map[string]interface{} // 扩展字段
+}
+
+// NewProblem 创建 Problem，Title 使用状态码对应的描述
+func NewProblem(status int, detail string) *Problem {
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// Error 实现 error 接口，handler 可以直接 c.Error(problem) 交给 ErrorHandler 处理
+func (p *Problem) Error() string {
+	if p.Detail == "" {
+		return p.Title
+	}
+	return p.Title + ": " + p.Detail
+}
+
+// MarshalJSON 将标准字段和扩展字段平铺输出，扩展字段不能覆盖标准字段
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	data := make(map[string]interface{}, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		data[k] = v
+	}
+	problemType := p.Type
+	if problemType == "" {
+		problemType = "about:blank"
+	}
+	data["type"] = problemType
+	if p.Title != "" {
+		data["title"] = p.Title
+	}
+	if p.Status != 0 {
+		data["status"] = p.Status
+	}
+	if p.Detail != "" {
+		data["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		data["instance"] = p.Instance
+	}
+	return json.Marshal(data)
+}
+
+// UnmarshalJSON 解析 problem+json，非标准字段放入 Extensions
+func (p *Problem) UnmarshalJSON(data []byte) error {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*p = Problem{}
+	for k, v := range raw {
+		switch k {
+		case "type":
+			p.Type, _ = v.(string)
+		case "title":
+			p.Title, _ = v.(string)
+		case "status":
+			if status, ok := v.(float64); ok {
+				p.Status = int(status)
+			}
+		case "detail":
+			p.Detail, _ = v.(string)
+		case "instance":
+			p.Instance, _ = v.(string)
+		default:
+			if p.Extensions == nil {
+				p.Extensions = make(map[string]interface{})
+			}
+			p.Extensions[k] = v
+		}
+	}
+	return nil
+}
+
+// Problem 以 application/problem+json 返回 p，状态码为 p.Status
+func (c *Context) Problem(p *Problem) {
+	if p.Status == 0 {
+		p.Status = http.StatusInternalServerError
+	}
+	c.SetHeader("Content-Type", MIMEProblemJSON)
+	c.Render(p.Status, render.JSON{Data: p})
+}
+
+// AbortWithProblem 中断后续处理并返回 p
+func (c *Context) AbortWithProblem(p *Problem) {
+	c.Abort()
+	c.Problem(p)
+}
+
+// useProblemDetails 框架生成的错误响应是否使用 problem+json
+func (c *Context) useProblemDetails() bool {
+	return c.engine != nil && c.engine.ProblemDetails
+}
@@ -0,0 +1,59 @@
+package tinyGin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProblemDetails(t *testing.T) {
+	r := New()
+	r.ProblemDetails = true
+	r.HandleMethodNotAllowed = true
+	r.Use(Recovery())
+	r.GET("/users", func(c *Context) {
+		c.Json(http.StatusOK, H{})
+	})
+	r.POST("/users", func(c *Context) {
+		var form struct {
+			Name string `json:"name" binding:"required"`
+		}
+		c.Bind(&form)
+	})
+	r.GET("/panic", func(c *Context) {
+		panic("boom")
+	})
+	cases := []struct {
+		method, path string
+		status       int
+		extension    string
+	}{
+		{"GET", "/missing", http.StatusNotFound, ""},
+		{"DELETE", "/users", http.StatusMethodNotAllowed, ""},
+		{"POST", "/users", http.StatusBadRequest, "errors"},
+		{"GET", "/panic", http.StatusInternalServerError, ""},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+		if ct := w.Header().Get("Content-Type"); ct != MIMEProblemJSON {
+			t.Errorf("%s %s: unexpected Content-Type %q", tc.method, tc.path, ct)
+		}
+		var p Problem
+		if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+			t.Fatal(err)
+		}
+		if w.Code != tc.status || p.Status != tc.status || p.Title != http.StatusText(tc.status) {
+			t.Errorf("%s %s: got %d %+v", tc.method, tc.path, w.Code, p)
+		}
+		if _, ok := p.Extensions[tc.extension]; tc.extension != "" && !ok {
+			t.Errorf("%s %s: missing extension %q", tc.method, tc.path, tc.extension)
+		}
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/users", nil))
+	if allow := w.Header().Get("Allow"); allow != "GET, POST" {
+		t.Fatalf("unexpected Allow header %q", allow)
+	}
+}
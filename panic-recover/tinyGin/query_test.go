@@ -0,0 +1,62 @@
+package tinyGin
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestQueryAccessors(t *testing.T) {
+	req := httptest.NewRequest("GET", "/?name=&id=1&id=2&filter[a]=1&filter[b]=2&filter=x", nil)
+	c := newContext(httptest.NewRecorder(), req)
+	if value, ok := c.GetQuery("name"); !ok || value != "" {
+		t.Fatal("name should exist with an empty value")
+	}
+	if _, ok := c.GetQuery("missing"); ok {
+		t.Fatal("missing should not exist")
+	}
+	if c.DefaultQuery("missing", "x") != "x" || c.DefaultQuery("name", "x") != "" {
+		t.Fatal("unexpected DefaultQuery")
+	}
+	if !reflect.DeepEqual(c.QueryArray("id"), []string{"1", "2"}) {
+		t.Fatalf("unexpected QueryArray %v", c.QueryArray("id"))
+	}
+	if !reflect.DeepEqual(c.QueryMap("filter"), map[string]string{"a": "1", "b": "2"}) {
+		t.Fatalf("unexpected QueryMap %v", c.QueryMap("filter"))
+	}
+	// 之后对 URL 的修改不会影响已经缓存的参数
+	c.Req.URL.RawQuery = ""
+	if c.Query("id") != "1" {
+		t.Fatal("query should be cached")
+	}
+}
+
+func TestPostFormAccessors(t *testing.T) {
+	body := strings.NewReader("user[name]=amadeus&user[age]=18&tag=a&tag=b&empty=")
+	req := httptest.NewRequest("POST", "/?tag=q", body)
+	req.Header.Set("Content-Type", MIMEPOSTForm)
+	c := newContext(httptest.NewRecorder(), req)
+	if !reflect.DeepEqual(c.PostFormArray("tag"), []string{"a", "b"}) {
+		t.Fatalf("PostForm should not include query values: %v", c.PostFormArray("tag"))
+	}
+	// PostForm 与 FormValue 一样，请求体中没有时使用 Query 参数
+	req = httptest.NewRequest("POST", "/?tag=q&page=2", strings.NewReader("tag=a"))
+	req.Header.Set("Content-Type", MIMEPOSTForm)
+	fallback := newContext(httptest.NewRecorder(), req)
+	if fallback.PostForm("tag") != "a" || fallback.PostForm("page") != "2" {
+		t.Fatalf("PostForm should prefer the body and fall back to the query: %q %q", fallback.PostForm("tag"), fallback.PostForm("page"))
+	}
+	if _, ok := fallback.GetPostForm("page"); ok {
+		t.Fatal("GetPostForm should only read the body")
+	}
+	if value, ok := c.GetPostForm("empty"); !ok || value != "" {
+		t.Fatal("empty should exist with an empty value")
+	}
+	if c.DefaultPostForm("missing", "x") != "x" {
+		t.Fatal("unexpected DefaultPostForm")
+	}
+	if !reflect.DeepEqual(c.PostFormMap("user"), map[string]string{"name": "amadeus", "age": "18"}) {
+		t.Fatalf("unexpected PostFormMap %v", c.PostFormMap("user"))
+	}
+}
@@ -0,0 +1,135 @@
+package ratelimit
+
+import (
+	"math"
+	"time"
+)
+
+// State 一个 key 的限流状态，由 Store 保存，不同的算法使用其中不同的字段
+type State struct {
+	// Tokens 令牌桶中剩余的令牌
+	Tokens float64
+	// Last 令牌桶上次补充令牌的时间，或者滑动窗口当前窗口的开始时间
+	Last time.Time
+	// Count 滑动窗口当前窗口内的请求数
+	Count int
+	// Prev 滑动窗口上一个窗口内的请求数
+	Prev int
+}
+
+// Result 一次限流判断的结果
+type Result struct {
+	// Allowed 是否允许这次请求
+	Allowed bool
+	// Limit 窗口内允许的请求数
+	Limit int
+	// Remaining 剩余的请求数
+	Remaining int
+	// Reset 额度完全恢复需要的时间
+	Reset time.Duration
+	// RetryAfter 请求被拒绝时，需要等待多久才能重试
+	RetryAfter time.Duration
+}
+
+// Algorithm 限流算法
+type Algorithm interface {
+	// Allow 根据 state 判断 now 时刻的请求是否允许，并更新 state
+	Allow(state *State, now time.Time) Result
+	// TTL 状态在多长时间没有更新后可以丢弃，丢弃之后与额度完全恢复的效果相同
+	TTL() time.Duration
+}
+
+// TokenBucket 令牌桶：每个 Period 补充 Limit 个令牌，最多积累 Burst 个，每个请求消耗一个令牌
+// 允许短时间的突发请求，长期来看平均速率不超过 Limit/Period
+type TokenBucket struct {
+	Limit  int
+	Period time.Duration
+	// Burst 桶的容量，为 0 时等于 Limit
+	Burst int
+}
+
+func (tb TokenBucket) capacity() float64 {
+	if tb.Burst > 0 {
+		return float64(tb.Burst)
+	}
+	return float64(tb.Limit)
+}
+
+// rate 每秒补充的令牌数
+func (tb TokenBucket) rate() float64 {
+	return float64(tb.Limit) / tb.Period.Seconds()
+}
+
+func (tb TokenBucket) Allow(s *State, now time.Time) Result {
+	capacity, rate := tb.capacity(), tb.rate()
+	if s.Last.IsZero() {
+		s.Tokens = capacity
+	} else if elapsed := now.Sub(s.Last).Seconds(); elapsed > 0 {
+		s.Tokens = math.Min(capacity, s.Tokens+elapsed*rate)
+	}
+	s.Last = now
+
+	res := Result{Limit: int(capacity)}
+	if s.Tokens >= 1 {
+		s.Tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = seconds((1 - s.Tokens) / rate)
+	}
+	res.Remaining = int(s.Tokens)
+	res.Reset = seconds((capacity - s.Tokens) / rate)
+	return res
+}
+
+func (tb TokenBucket) TTL() time.Duration {
+	return seconds(tb.capacity() / tb.rate())
+}
+
+// SlidingWindow 滑动窗口：任意 Window 长度的时间内最多允许 Limit 个请求
+// 使用当前窗口和上一个窗口的计数按时间加权估算，只需要保存两个计数
+type SlidingWindow struct {
+	Limit  int
+	Window time.Duration
+}
+
+func (sw SlidingWindow) Allow(s *State, now time.Time) Result {
+	start := now.Truncate(sw.Window)
+	if !s.Last.Equal(start) {
+		// 进入了新的窗口
+		if s.Last.Add(sw.Window).Equal(start) {
+			s.Prev = s.Count
+		} else {
+			s.Prev = 0
+		}
+		s.Count = 0
+		s.Last = start
+	}
+	elapsed := now.Sub(start)
+	weight := 1 - float64(elapsed)/float64(sw.Window)
+	estimated := float64(s.Prev)*weight + float64(s.Count)
+
+	res := Result{Limit: sw.Limit, Reset: start.Add(sw.Window).Sub(now)}
+	if estimated+1 <= float64(sw.Limit) {
+		s.Count++
+		res.Allowed = true
+		res.Remaining = int(float64(sw.Limit) - estimated - 1)
+		return res
+	}
+	// 等到上一个窗口的权重下降到足够放行一个请求，当前窗口已经用完额度时只能等到下一个窗口
+	if s.Prev > 0 && s.Count+1 <= sw.Limit {
+		need := 1 - float64(sw.Limit-s.Count-1)/float64(s.Prev)
+		res.RetryAfter = time.Duration(need*float64(sw.Window)) - elapsed
+	} else {
+		res.RetryAfter = res.Reset
+	}
+	return res
+}
+
+func (sw SlidingWindow) TTL() time.Duration {
+	return 2 * sw.Window
+}
+
+// seconds 将秒数转换为 time.Duration
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
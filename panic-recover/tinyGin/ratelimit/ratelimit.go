@@ -0,0 +1,135 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"tinyGin"
+)
+
+/*
+ratelimit 实现按客户端限流的中间件。
+
+	store := ratelimit.NewMemoryStore(0)
+	defer store.Close()
+	api.Use(ratelimit.New(ratelimit.Config{
+		Algorithm: ratelimit.TokenBucket{Limit: 100, Period: time.Minute, Burst: 20},
+		Store:     store,
+		Key:       ratelimit.Combine(ratelimit.ByRoute, ratelimit.ByIP),
+	}))
+
+每个响应都带有 RateLimit-Limit、RateLimit-Remaining 和 RateLimit-Reset 响应头，
+超过限制时返回 429 Too Many Requests，并通过 Retry-After 告诉客户端多少秒之后可以重试。
+*/
+
+// KeyFunc 返回请求所属的限流 key，返回空字符串时不限流
+type KeyFunc func(c *tinyGin.Context) string
+
+// ByIP 按客户端 IP 限流，部署在反向代理之后时需要先配置 Engine.SetTrustedProxies
+func ByIP(c *tinyGin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// ByUser 按鉴权中间件保存的当前用户限流，没有登录的请求按 IP 限流
+func ByUser(c *tinyGin.Context) string {
+	if user, ok := c.Get(tinyGin.AuthUserKey); ok && user != nil && user != "" {
+		return fmt.Sprintf("user:%v", user)
+	}
+	return ByIP(c)
+}
+
+// ByRoute 按路由限流，所有客户端共享同一个路由的额度
+func ByRoute(c *tinyGin.Context) string {
+	return "route:" + c.Method + " " + c.FullPath()
+}
+
+// Combine 组合多个 KeyFunc，例如 Combine(ByRoute, ByIP) 表示每个客户端在每个路由上单独限流
+func Combine(fns ...KeyFunc) KeyFunc {
+	return func(c *tinyGin.Context) string {
+		parts := make([]string, 0, len(fns))
+		for _, fn := range fns {
+			key := fn(c)
+			if key == "" {
+				return ""
+			}
+			parts = append(parts, key)
+		}
+		return strings.Join(parts, "|")
+	}
+}
+
+// Config 限流中间件的配置
+type Config struct {
+	// Algorithm 限流算法，TokenBucket 或者 SlidingWindow
+	Algorithm Algorithm
+	// Store 限流状态的存储，多个中间件共享 Store 时，Key 返回的 key 不能重复
+	// 为 nil 时新建一个 MemoryStore，它的清理协程会一直运行到进程退出，没有办法关闭；
+	// 中间件会被多次创建时(例如在测试中)，应当自己创建 Store 并在不再使用时调用 Close
+	Store Store
+	// Key 请求所属的限流 key，默认为 ByIP
+	Key KeyFunc
+	// Now 返回当前时间，默认为 time.Now
+	Now func() time.Time
+}
+
+// New 按照 conf 创建限流中间件，没有设置 Algorithm 或者 Algorithm 的参数不合法时 panic
+// Store 出错时放行请求，错误记录在 c.Errors 中，避免存储故障导致整个服务不可用
+func New(conf Config) tinyGin.HandlerFunc {
+	switch a := conf.Algorithm.(type) {
+	case nil:
+		panic("ratelimit: Config.Algorithm is required")
+	case TokenBucket:
+		if a.Limit <= 0 || a.Period <= 0 || a.Burst < 0 {
+			panic("ratelimit: TokenBucket requires Limit > 0, Period > 0 and Burst >= 0")
+		}
+	case SlidingWindow:
+		if a.Limit <= 0 || a.Window <= 0 {
+			panic("ratelimit: SlidingWindow requires Limit > 0 and Window > 0")
+		}
+	}
+	if conf.Store == nil {
+		conf.Store = NewMemoryStore(0)
+	}
+	if conf.Key == nil {
+		conf.Key = ByIP
+	}
+	if conf.Now == nil {
+		conf.Now = time.Now
+	}
+	ttl := conf.Algorithm.TTL()
+	return func(c *tinyGin.Context) {
+		key := conf.Key(c)
+		if key == "" {
+			c.Next()
+			return
+		}
+		var res Result
+		now := conf.Now()
+		err := conf.Store.Update(key, now, ttl, func(state *State) {
+			res = conf.Algorithm.Allow(state, now)
+		})
+		if err != nil {
+			c.Error(err)
+			c.Next()
+			return
+		}
+		header := c.Writer.Header()
+		header.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+		header.Set("RateLimit-Remaining", strconv.Itoa(max(res.Remaining, 0)))
+		header.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+		if !res.Allowed {
+			header.Set("Retry-After", strconv.Itoa(max(ceilSeconds(res.RetryAfter), 1)))
+			c.Fail(http.StatusTooManyRequests, "Too Many Requests")
+			return
+		}
+		c.Next()
+	}
+}
+
+// ceilSeconds 向上取整到秒
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,167 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"tinyGin"
+)
+
+func TestTokenBucket(t *testing.T) {
+	tb := TokenBucket{Limit: 1, Period: time.Second, Burst: 3}
+	var s State
+	now := time.Unix(1700000000, 0)
+	for i := 0; i < 3; i++ {
+		if res := tb.Allow(&s, now); !res.Allowed || res.Remaining != 2-i {
+			t.Fatalf("request %d: %+v", i, res)
+		}
+	}
+	res := tb.Allow(&s, now)
+	if res.Allowed || res.RetryAfter != time.Second || res.Reset != 3*time.Second {
+		t.Fatalf("burst exhausted: %+v", res)
+	}
+	// 半秒之后还不够一个令牌，一秒之后补充了一个
+	if res := tb.Allow(&s, now.Add(500*time.Millisecond)); res.Allowed || res.RetryAfter != 500*time.Millisecond {
+		t.Fatalf("after 500ms: %+v", res)
+	}
+	if res := tb.Allow(&s, now.Add(time.Second)); !res.Allowed || res.Remaining != 0 {
+		t.Fatalf("after 1s: %+v", res)
+	}
+	if tb.TTL() != 3*time.Second {
+		t.Fatalf("unexpected TTL %v", tb.TTL())
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	sw := SlidingWindow{Limit: 4, Window: time.Minute}
+	var s State
+	start := time.Unix(1700000040, 0).Truncate(time.Minute)
+	for i := 0; i < 4; i++ {
+		if res := sw.Allow(&s, start.Add(time.Duration(i)*time.Second)); !res.Allowed {
+			t.Fatalf("request %d: %+v", i, res)
+		}
+	}
+	res := sw.Allow(&s, start.Add(30*time.Second))
+	if res.Allowed || res.RetryAfter != 30*time.Second || res.Reset != 30*time.Second {
+		t.Fatalf("window exhausted: %+v", res)
+	}
+	// 下一个窗口刚开始时，上一个窗口的 4 个请求还占着几乎全部额度
+	if res := sw.Allow(&s, start.Add(time.Minute)); res.Allowed || res.RetryAfter != 15*time.Second {
+		t.Fatalf("start of next window: %+v", res)
+	}
+	// 过了一半，上一个窗口按 2 个请求计算
+	if res := sw.Allow(&s, start.Add(90*time.Second)); !res.Allowed || res.Remaining != 1 {
+		t.Fatalf("middle of next window: %+v", res)
+	}
+	// 隔了不止一个窗口，额度完全恢复
+	if res := sw.Allow(&s, start.Add(5*time.Minute)); !res.Allowed || res.Remaining != 3 {
+		t.Fatalf("after idle: %+v", res)
+	}
+}
+
+func TestMemoryStoreEviction(t *testing.T) {
+	s := NewMemoryStore(2)
+	defer s.Close()
+	now := time.Unix(1700000000, 0)
+	incr := func(key string) int {
+		var count int
+		s.Update(key, now, time.Minute, func(state *State) {
+			state.Count++
+			count = state.Count
+		})
+		return count
+	}
+	incr("a")
+	incr("b")
+	incr("a")
+	incr("c") // 淘汰最久没有访问的 b
+	if s.Len() != 2 || incr("a") != 3 || incr("b") != 1 {
+		t.Fatal("least recently used key should be evicted")
+	}
+	s.Update("expired", now, -time.Second, func(state *State) { state.Count = 10 })
+	if incr("expired") != 1 {
+		t.Fatal("expired state should be reset")
+	}
+	// 过期只看传入的时间，与系统时钟无关
+	now = now.Add(30 * time.Second)
+	if incr("b") != 2 {
+		t.Fatal("state should be kept within ttl")
+	}
+	now = now.Add(2 * time.Minute)
+	if incr("b") != 1 {
+		t.Fatal("state should expire by the caller's clock")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	store := NewMemoryStore(0)
+	defer store.Close()
+	now := time.Unix(1700000000, 0)
+	r := tinyGin.New()
+	r.Use(func(c *tinyGin.Context) {
+		if user := c.Query("user"); user != "" {
+			c.Set(tinyGin.AuthUserKey, user)
+		}
+		c.Next()
+	})
+	r.Use(New(Config{
+		Algorithm: TokenBucket{Limit: 2, Period: time.Minute},
+		Store:     store,
+		Key:       Combine(ByRoute, ByUser),
+		Now:       func() time.Time { return now },
+	}))
+	r.GET("/users/:id", func(c *tinyGin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	r.GET("/orders", func(c *tinyGin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	do := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	do("/users/1")
+	w := do("/users/2") // 同一个路由模式共享额度
+	if w.Code != http.StatusOK || w.Header().Get("RateLimit-Limit") != "2" || w.Header().Get("RateLimit-Remaining") != "0" ||
+		w.Header().Get("RateLimit-Reset") != "60" {
+		t.Fatalf("unexpected headers %d %v", w.Code, w.Header())
+	}
+	w = do("/users/3")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "30" {
+		t.Fatalf("expected 429, got %d %v", w.Code, w.Header())
+	}
+	if w := do("/orders"); w.Code != http.StatusOK {
+		t.Fatalf("other routes have their own quota, got %d", w.Code)
+	}
+	if w := do("/users/3?user=amadeus"); w.Code != http.StatusOK {
+		t.Fatalf("other users have their own quota, got %d", w.Code)
+	}
+	now = now.Add(30 * time.Second)
+	if w := do("/users/3"); w.Code != http.StatusOK {
+		t.Fatalf("quota should be refilled, got %d", w.Code)
+	}
+}
+
+func TestNewValidatesAlgorithm(t *testing.T) {
+	store := NewMemoryStore(0)
+	defer store.Close()
+	for _, algorithm := range []Algorithm{
+		TokenBucket{Limit: 0, Period: time.Minute},
+		TokenBucket{Limit: 10},
+		TokenBucket{Limit: 10, Period: time.Minute, Burst: -1},
+		SlidingWindow{Limit: 10},
+		SlidingWindow{Window: time.Minute},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%+v should be rejected", algorithm)
+				}
+			}()
+			New(Config{Algorithm: algorithm, Store: store})
+		}()
+	}
+}
@@ -0,0 +1,120 @@
+package ratelimit
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Store 保存每个 key 的限流状态，实现这个接口就可以在多个实例之间共享限流状态
+type Store interface {
+	// Update 原子地读取并修改 key 的状态，key 不存在时 fn 收到的是零值
+	// fn 返回后状态需要从 now 开始保存 ttl 时间，之后可以丢弃；
+	// now 与传给 Algorithm 的是同一个时间，Store 不应该自己读取时钟
+	Update(key string, now time.Time, ttl time.Duration, fn func(state *State)) error
+}
+
+// defaultMaxKeys MemoryStore 默认最多保存的 key 数量
+const defaultMaxKeys = 100000
+
+// sweepInterval MemoryStore 清理过期状态的间隔
+const sweepInterval = time.Minute
+
+// MemoryStore 把限流状态保存在进程内存中
+// 过期的状态由后台协程定期清理；key 的数量超过上限时淘汰最久没有访问的 key，防止伪造大量客户端耗尽内存
+// 过期时间都按 Update 传入的 now 计算，后台清理时使用最近一次 Update 的 now，不读取系统时钟
+type MemoryStore struct {
+	maxKeys int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List // 最近访问的在前面
+	now     time.Time  // 最近一次 Update 传入的时间
+	done    chan struct{}
+	once    sync.Once
+}
+
+type memoryEntry struct {
+	key     string
+	state   State
+	expires time.Time
+}
+
+// NewMemoryStore 创建最多保存 maxKeys 个 key 的 MemoryStore，maxKeys 为 0 时使用默认值 100000
+// 会启动清理过期状态的协程，不再使用时需要调用 Close
+func NewMemoryStore(maxKeys int) *MemoryStore {
+	if maxKeys <= 0 {
+		maxKeys = defaultMaxKeys
+	}
+	s := &MemoryStore{
+		maxKeys: maxKeys,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+		done:    make(chan struct{}),
+	}
+	go s.sweep()
+	return s
+}
+
+func (s *MemoryStore) Update(key string, now time.Time, ttl time.Duration, fn func(state *State)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.After(s.now) {
+		s.now = now
+	}
+	elem, ok := s.entries[key]
+	if ok && now.After(elem.Value.(*memoryEntry).expires) {
+		// 已经过期，相当于新的 key
+		elem.Value.(*memoryEntry).state = State{}
+	}
+	if !ok {
+		if s.lru.Len() >= s.maxKeys {
+			oldest := s.lru.Back()
+			s.lru.Remove(oldest)
+			delete(s.entries, oldest.Value.(*memoryEntry).key)
+		}
+		elem = s.lru.PushFront(&memoryEntry{key: key})
+		s.entries[key] = elem
+	} else {
+		s.lru.MoveToFront(elem)
+	}
+	entry := elem.Value.(*memoryEntry)
+	fn(&entry.state)
+	entry.expires = now.Add(ttl)
+	return nil
+}
+
+// Len 返回当前保存的 key 数量
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lru.Len()
+}
+
+// Close 停止清理协程
+func (s *MemoryStore) Close() {
+	s.once.Do(func() {
+		close(s.done)
+	})
+}
+
+// sweep 定期清理过期的状态
+func (s *MemoryStore) sweep() {
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			for key, elem := range s.entries {
+				if s.now.After(elem.Value.(*memoryEntry).expires) {
+					s.lru.Remove(elem)
+					delete(s.entries, key)
+				}
+			}
+			s.mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package tinyGin
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+)
+
+/*
+请求体只能读取一次，一个中间件(例如校验签名)读取之后，后面的 handler 再做参数绑定就只能读到空内容。
+GetRawData 读取请求体后缓存在 Context 中，并把 c.Req.Body 替换成缓存的副本，之后的读取都能拿到完整的内容。
+为了避免把超大的请求体读进内存，读取的字节数受 Engine.MaxRawDataSize 限制，超过限制时 c.Req.Body 保持完整，仍然可以直接读取。
+*/
+
+// defaultMaxRawDataSize Engine.MaxRawDataSize 的默认值
+const defaultMaxRawDataSize = 10 << 20 // 10 MB
+
+// ErrBodyTooLarge 请求体超过 Engine.MaxRawDataSize，交给 ErrorHandler 处理时返回 413
+var ErrBodyTooLarge = NewHTTPError(http.StatusRequestEntityTooLarge, "request body too large")
+
+// GetRawData 返回请求体的内容，多次调用只会读取一次
+func (c *Context) GetRawData() ([]byte, error) {
+	if c.rawData != nil {
+		return c.rawData, nil
+	}
+	if c.Req.Body == nil || c.Req.Body == http.NoBody {
+		c.rawData = []byte{}
+		return c.rawData, nil
+	}
+	limit := c.maxRawDataSize()
+	// 多读一个字节，用来判断请求体是否超过了限制
+	data, err := io.ReadAll(io.LimitReader(c.Req.Body, limit+1))
+	if err != nil || int64(len(data)) > limit {
+		// 把已经读出的内容放回去，之后的读取仍然从请求体的开头开始
+		c.Req.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(data), c.Req.Body), c.Req.Body}
+		if err != nil {
+			return nil, err
+		}
+		return nil, ErrBodyTooLarge
+	}
+	c.Req.Body.Close()
+	c.rawData = data
+	c.Req.Body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
+
+func (c *Context) maxRawDataSize() int64 {
+	if c.engine != nil && c.engine.MaxRawDataSize > 0 {
+		return c.engine.MaxRawDataSize
+	}
+	return defaultMaxRawDataSize
+}
+
+// ShouldBindBodyWith 与 ShouldBindWith 类似，但请求体会缓存在 Context 中，可以多次绑定到不同的结构体
+func (c *Context) ShouldBindBodyWith(obj interface{}, bb BindingBody) error {
+	body, err := c.GetRawData()
+	if err != nil {
+		return err
+	}
+	if err := bb.BindBody(body, obj); err != nil {
+		return err
+	}
+	return validate(obj)
+}
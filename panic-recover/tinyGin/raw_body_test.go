@@ -0,0 +1,110 @@
+package tinyGin
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestShouldBindBodyWith(t *testing.T) {
+	r := New()
+	r.Use(func(c *Context) {
+		// 模拟校验签名的中间件先读取了请求体
+		if _, err := c.GetRawData(); err != nil {
+			c.Fail(http.StatusRequestEntityTooLarge, err.Error())
+			return
+		}
+		c.Next()
+	})
+	r.POST("/login", func(c *Context) {
+		var a struct {
+			Name string `json:"name" binding:"required"`
+		}
+		var b struct {
+			Age int `json:"age"`
+		}
+		if err := c.ShouldBindBodyWith(&a, JSON); err != nil {
+			c.Fail(http.StatusBadRequest, err.Error())
+			return
+		}
+		if err := c.ShouldBindBodyWith(&b, JSON); err != nil {
+			c.Fail(http.StatusBadRequest, err.Error())
+			return
+		}
+		c.String(http.StatusOK, "%s %d", a.Name, b.Age)
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/login", strings.NewReader(`{"name":"amadeus","age":18}`)))
+	if w.Body.String() != "amadeus 18" {
+		t.Fatalf("unexpected body %q", w.Body.String())
+	}
+
+	r.MaxRawDataSize = 8
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/login", strings.NewReader(`{"name":"amadeus"}`)))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %d", w.Code)
+	}
+}
+
+func TestGetRawDataRestoresBody(t *testing.T) {
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"name":"amadeus"}`))
+	c := newContext(httptest.NewRecorder(), req)
+	if _, err := c.GetRawData(); err != nil {
+		t.Fatal(err)
+	}
+	var obj struct {
+		Name string `json:"name"`
+	}
+	if err := c.ShouldBindJSON(&obj); err != nil || obj.Name != "amadeus" {
+		t.Fatalf("body should be readable again: %v %+v", err, obj)
+	}
+
+	c = newContext(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader("ab")))
+	c.engine = New()
+	c.engine.MaxRawDataSize = 1
+	if _, err := c.GetRawData(); !errors.Is(err, ErrBodyTooLarge) {
+		t.Fatalf("expected ErrBodyTooLarge, got %v", err)
+	}
+	// 超过限制时读出的内容会放回请求体
+	if data, _ := io.ReadAll(c.Req.Body); string(data) != "ab" {
+		t.Fatalf("body should be intact after ErrBodyTooLarge, got %q", data)
+	}
+}
+
+// flakyReader 第一次读取返回 3 个字节和一个错误，之后正常读取剩下的内容
+type flakyReader struct {
+	rest   string
+	failed bool
+}
+
+func (r *flakyReader) Read(p []byte) (int, error) {
+	if r.rest == "" {
+		return 0, io.EOF
+	}
+	chunk := r.rest
+	if !r.failed {
+		chunk = chunk[:3]
+	}
+	n := copy(p, chunk)
+	r.rest = r.rest[n:]
+	if !r.failed {
+		r.failed = true
+		return n, errors.New("temporary failure")
+	}
+	return n, nil
+}
+
+func TestGetRawDataRestoresBodyOnError(t *testing.T) {
+	req := httptest.NewRequest("POST", "/", io.NopCloser(&flakyReader{rest: "abcdef"}))
+	c := newContext(httptest.NewRecorder(), req)
+	if _, err := c.GetRawData(); err == nil {
+		t.Fatal("read error should be returned")
+	}
+	if data, _ := io.ReadAll(c.Req.Body); string(data) != "abcdef" {
+		t.Fatalf("bytes read before the error should be restored, got %q", data)
+	}
+}
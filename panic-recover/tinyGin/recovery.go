@@ -1,43 +1,210 @@
 package tinyGin
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"net"
 	"net/http"
-	"runtime"
+	"net/http/httputil"
+	"net/url"
+	"os"
 	"strings"
+	"syscall"
+	"tinyGin/render"
 )
 
+/*
+Recovery 中间件捕获处理请求时发生的 panic，记录日志并返回 500，避免整个服务因为一个请求崩溃。
+几种特殊情况：
+  - 客户端已经断开连接(broken pipe、connection reset)时，写响应没有意义，只记录错误并中止处理；
+  - panic(http.ErrAbortHandler) 是 net/http 约定的主动中止请求的方式，原样重新抛出，交给 net/http 处理；
+  - 响应头已经写出时无法再修改状态码，只记录日志，不再写响应。
+日志中包含请求的内容，Authorization、Cookie 等敏感请求头以及请求行中 token、api_key 等敏感的 Query 参数会被替换为 *。
+*/
+
+// RecoveryFunc 发生 panic 时的处理函数，err 是 recover() 的返回值
+type RecoveryFunc func(c *Context, err interface{})
+
+// sensitiveHeaders 记录日志时需要隐藏的请求头
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "X-Api-Key", "X-Auth-Token"}
+
+// sensitiveQueryParams 记录日志时需要隐藏的 Query 参数，不区分大小写
+var sensitiveQueryParams = []string{"access_token", "api_key", "apikey", "token", "password", "secret", "signature"}
+
+// RecoveryConfig Recovery 中间件的配置
+type RecoveryConfig struct {
+	// Output 日志输出的位置，为 nil 时使用标准库 log
+	Output io.Writer
+	// Handle 处理 panic，默认返回 500
+	Handle RecoveryFunc
+	// FullStack 为 true 时记录完整的调用栈，否则最多记录 32 帧
+	FullStack bool
+	// SourceLines 应用代码的每一帧在出错行前后各显示的源码行数，默认为 0，不显示源码
+	SourceLines int
+	// DevPage 为 true 时返回包含调用栈和请求信息的 HTML 页面，会暴露源码，只应在开发环境使用
+	// 设置了 Handle 时不生效
+	DevPage bool
+}
+
+// Recovery 使用默认配置的错误恢复中间件，日志输出到标准库 log
 func Recovery() HandlerFunc {
+	return RecoveryWithWriter(nil)
+}
+
+// CustomRecovery 使用 handle 处理 panic 的错误恢复中间件，例如返回自定义的错误页面
+func CustomRecovery(handle RecoveryFunc) HandlerFunc {
+	return RecoveryWithWriter(nil, handle)
+}
+
+// RecoveryWithWriter 日志输出到 out 的错误恢复中间件，out 为 nil 时使用标准库 log，recovery 为空时返回 500
+func RecoveryWithWriter(out io.Writer, recovery ...RecoveryFunc) HandlerFunc {
+	conf := RecoveryConfig{Output: out}
+	if len(recovery) > 0 {
+		conf.Handle = recovery[0]
+	}
+	return RecoveryWithConfig(conf)
+}
+
+// RecoveryWithConfig 按照 conf 创建错误恢复中间件
+func RecoveryWithConfig(conf RecoveryConfig) HandlerFunc {
+	handle := conf.Handle
+	if handle == nil {
+		handle = defaultHandleRecovery
+	}
+	logger := log.Default()
+	if conf.Output != nil {
+		logger = log.New(conf.Output, "", log.LstdFlags)
+	}
 	return func(c *Context) {
 		// 使用 defer 挂载上错误恢复的函数
 		defer func() {
-			// 在这个函数中调用 recover()，捕获 panic，并且将堆栈信息打印在日志中，向用户返回 Internal Server Error
-			if err := recover(); err != nil {
-				message := fmt.Sprintf("%s", err)
-				log.Printf("%s\n\n", trace(message))
-				c.Fail(http.StatusInternalServerError, "Internal Server Error")
+			// 在这个函数中调用 recover()，捕获 panic，并且将堆栈信息打印在日志中
+			err := recover()
+			if err == nil {
+				return
+			}
+			// Timeout 中间件转交过来的 panic，调用栈要用 handler 协程中记录的
+			var pcs []uintptr
+			if p, ok := err.(*handlerPanic); ok {
+				err, pcs = p.value, p.pcs
+			}
+			if err == http.ErrAbortHandler {
+				panic(err)
+			}
+			request := dumpRequest(c.Req)
+			if brokenPipe(err) {
+				// 连接已经断开，堆栈信息没有意义，也不能再写响应
+				logger.Printf("[Recovery] connection lost: %v\n%s", err, request)
+				if e, ok := err.(error); ok {
+					c.Error(e)
+				}
+				c.Abort()
+				return
+			}
+			message := fmt.Sprintf("%s", err)
+			var stack []stackFrame
+			if pcs != nil {
+				stack = stackFrames(pcs, conf.FullStack, conf.SourceLines)
+			} else {
+				// 跳过 runtime.Callers 和 callers 本身
+				stack = callers(2, conf.FullStack, conf.SourceLines)
+			}
+			logger.Printf("[Recovery] panic recovered:\n%s\n%s\n\n", request, formatStack(message, stack))
+			if c.Writer.Written() {
+				// 响应头已经写出，只能中止后续的处理
+				c.Abort()
+				return
 			}
+			if conf.DevPage && conf.Handle == nil {
+				c.Render(http.StatusInternalServerError, render.HTML{
+					Template: panicPage,
+					Data:     H{"Message": message, "Stack": stack, "Request": request},
+				})
+				c.Abort()
+				return
+			}
+			handle(c, err)
 		}()
 		c.Next()
 	}
 }
 
-// print stack trace for debug
-// 获取触发 panic 的堆栈信息
-func trace(message string) string {
-	var pcs [32]uintptr
-	// Callers 用来返回调用栈的程序计数器, 第 0 个 Caller 是 Callers 本身，第 1 个是上一层 trace，第 2 个是再上一层的 defer func。
-	// 因此，为了日志简洁一点，我们跳过了前 3 个 Caller
-	n := runtime.Callers(3, pcs[:]) // skip first 3 caller
-	var str strings.Builder
-	str.WriteString(message + "\nTraceback:")
-	for _, pc := range pcs[:n] {
-		// 通过 runtime.FuncForPC(pc) 获取对应的函数
-		fn := runtime.FuncForPC(pc)
-		// 通过 fn.FileLine(pc) 获取到调用该函数的文件名和行号，打印在日志中。
-		file, line := fn.FileLine(pc)
-		str.WriteString(fmt.Sprintf("\n\t%s:%d", file, line))
-	}
-	return str.String()
+// defaultHandleRecovery 向用户返回 Internal Server Error
+func defaultHandleRecovery(c *Context, _ interface{}) {
+	c.Fail(http.StatusInternalServerError, "Internal Server Error")
+}
+
+// brokenPipe 判断 panic 是否是因为客户端断开连接，此时写响应会得到 EPIPE 或 ECONNRESET
+func brokenPipe(err interface{}) bool {
+	e, ok := err.(error)
+	if !ok {
+		return false
+	}
+	var opErr *net.OpError
+	if !errors.As(e, &opErr) {
+		return false
+	}
+	var sysErr *os.SyscallError
+	if errors.As(opErr, &sysErr) {
+		return errors.Is(sysErr, syscall.EPIPE) || errors.Is(sysErr, syscall.ECONNRESET)
+	}
+	msg := strings.ToLower(opErr.Error())
+	return strings.Contains(msg, "broken pipe") || strings.Contains(msg, "connection reset by peer")
+}
+
+// dumpRequest 返回请求行和请求头，不包含请求体，敏感请求头和 Query 参数的值替换为 *
+func dumpRequest(req *http.Request) string {
+	if req == nil {
+		return ""
+	}
+	dump, err := httputil.DumpRequest(req, false)
+	if err != nil {
+		return ""
+	}
+	lines := strings.Split(strings.TrimRight(string(dump), "\r\n"), "\r\n")
+	lines[0] = maskRequestLine(lines[0])
+	for i := 1; i < len(lines); i++ {
+		key, _, ok := strings.Cut(lines[i], ":")
+		if !ok {
+			continue
+		}
+		for _, header := range sensitiveHeaders {
+			if strings.EqualFold(strings.TrimSpace(key), header) {
+				lines[i] = key + ": *"
+				break
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// maskRequestLine 把请求行 GET /path?token=xxx HTTP/1.1 中敏感参数的值替换为 *，其他参数保持原样
+func maskRequestLine(line string) string {
+	uri, query, ok := strings.Cut(line, "?")
+	if !ok {
+		return line
+	}
+	query, proto, _ := strings.Cut(query, " ")
+	return uri + "?" + maskQuery(query) + " " + proto
+}
+
+// maskQuery 把原始 Query 字符串中敏感参数的值替换为 *，其他参数保持原样，日志中记录 Query 时都要经过这里
+func maskQuery(query string) string {
+	params := strings.Split(query, "&")
+	for i, param := range params {
+		key, _, _ := strings.Cut(param, "=")
+		name, err := url.QueryUnescape(key)
+		if err != nil {
+			name = key
+		}
+		for _, sensitive := range sensitiveQueryParams {
+			if strings.EqualFold(name, sensitive) {
+				params[i] = key + "=*"
+				break
+			}
+		}
+	}
+	return strings.Join(params, "&")
 }
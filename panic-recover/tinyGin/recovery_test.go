@@ -0,0 +1,122 @@
+package tinyGin
+
+import (
+	"bytes"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestRecoveryWithWriter(t *testing.T) {
+	var buf bytes.Buffer
+	r := New()
+	r.Use(RecoveryWithWriter(&buf))
+	r.GET("/panic", func(c *Context) {
+		panic("boom")
+	})
+	r.GET("/written", func(c *Context) {
+		c.String(http.StatusOK, "partial")
+		panic("too late")
+	})
+	r.GET("/broken", func(c *Context) {
+		panic(&net.OpError{Op: "write", Err: os.NewSyscallError("write", syscall.EPIPE)})
+	})
+
+	req := httptest.NewRequest("GET", "/panic", nil)
+	req.Header.Set("Authorization", "Bearer secret-token")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("unexpected status %d", w.Code)
+	}
+	log := buf.String()
+	if !strings.Contains(log, "boom") || !strings.Contains(log, "Authorization: *") || strings.Contains(log, "secret-token") {
+		t.Fatalf("unexpected log %q", log)
+	}
+	// 默认不在日志中附带源码
+	if strings.Contains(log, `panic("boom")`) {
+		t.Fatalf("source lines should be opt-in: %q", log)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/written", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "partial" {
+		t.Fatalf("response should not be rewritten: %d %q", w.Code, w.Body.String())
+	}
+
+	buf.Reset()
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/broken", nil))
+	if w.Body.Len() != 0 || !strings.Contains(buf.String(), "connection lost") || strings.Contains(buf.String(), "Traceback") {
+		t.Fatalf("broken pipe should not write a response: %q %q", w.Body.String(), buf.String())
+	}
+}
+
+func TestCustomRecovery(t *testing.T) {
+	r := New()
+	r.Use(RecoveryWithWriter(&bytes.Buffer{}, func(c *Context, err interface{}) {
+		c.String(http.StatusServiceUnavailable, "custom: %v", err)
+	}))
+	r.GET("/panic", func(c *Context) {
+		panic("boom")
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/panic", nil))
+	if w.Code != http.StatusServiceUnavailable || w.Body.String() != "custom: boom" {
+		t.Fatalf("unexpected response %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestRecoveryErrAbortHandler(t *testing.T) {
+	r := New()
+	r.Use(Recovery())
+	r.GET("/abort", func(c *Context) {
+		panic(http.ErrAbortHandler)
+	})
+	defer func() {
+		if err := recover(); err != http.ErrAbortHandler {
+			t.Fatalf("expected http.ErrAbortHandler, got %v", err)
+		}
+	}()
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/abort", nil))
+}
+
+func TestRecoveryStack(t *testing.T) {
+	var buf bytes.Buffer
+	r := New()
+	r.Use(RecoveryWithConfig(RecoveryConfig{Output: &buf, SourceLines: 1, DevPage: true}))
+	r.GET("/panic", func(c *Context) {
+		panic("<boom>") // panic here
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/panic?token=1", nil))
+
+	log := buf.String()
+	if !strings.Contains(log, "tinyGin.TestRecoveryStack.func1") || !strings.Contains(log, `panic("<boom>") // panic here`) {
+		t.Fatalf("stack should contain the handler and its source: %s", log)
+	}
+	if strings.Contains(log, "runtime.gopanic") || strings.Contains(log, "tinyGin.(*Context).Next") {
+		t.Fatalf("runtime and framework frames should be filtered: %s", log)
+	}
+	body := w.Body.String()
+	if w.Code != http.StatusInternalServerError || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") ||
+		!strings.Contains(body, "panic: &lt;boom&gt;") || !strings.Contains(body, "GET /panic?token=*") {
+		t.Fatalf("unexpected panic page %d %s", w.Code, body)
+	}
+}
+
+func TestDumpRequestMasksQuery(t *testing.T) {
+	req := httptest.NewRequest("GET", "/search?q=go&API_KEY=abc123&access%5Ftoken=xyz&page=2", nil)
+	dump := dumpRequest(req)
+	line, _, _ := strings.Cut(dump, "\n")
+	if line != "GET /search?q=go&API_KEY=*&access%5Ftoken=*&page=2 HTTP/1.1" {
+		t.Fatalf("unexpected request line %q", line)
+	}
+	if strings.Contains(dump, "abc123") || strings.Contains(dump, "xyz") {
+		t.Fatalf("sensitive query values should be masked: %q", dump)
+	}
+}
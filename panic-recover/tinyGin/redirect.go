@@ -0,0 +1,60 @@
+package tinyGin
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// maxForwards 一个请求最多内部转发的次数，防止路由之间互相转发造成死循环
+const maxForwards = 10
+
+// Redirect 重定向到 location，code 必须是 3xx(或者 201 Created)，否则 panic
+// 与直接调用 http.Redirect 不同，状态码会记录在 c.Writer 中，Logger 等中间件能够拿到
+func (c *Context) Redirect(code int, location string) {
+	if (code < http.StatusMultipleChoices || code > http.StatusPermanentRedirect) && code != http.StatusCreated {
+		panic(fmt.Sprintf("tinyGin: cannot redirect with status code %d", code))
+	}
+	http.Redirect(c.Writer, c.Req, location, code)
+}
+
+// Forward 内部重定向：把请求交给 path 对应的路由重新处理，客户端不会感知到
+// path 可以带有 Query 参数，此时会替换原来的 Query 参数；请求方法、请求头和请求体保持不变
+// 原来的路由已经执行过的分组中间件(例如 Logger、Recovery、Sessions)不会再执行，只执行新路由所在的分组独有的中间件和处理函数，
+// 当前 handler 之后的中间件和处理函数不再执行
+func (c *Context) Forward(path string) {
+	if c.forwards >= maxForwards {
+		c.Fail(http.StatusInternalServerError, "too many internal forwards")
+		return
+	}
+	u, err := url.Parse(path)
+	if err != nil {
+		panic(fmt.Sprintf("tinyGin: invalid forward path %q: %v", path, err))
+	}
+	c.forwards++
+	c.Req.URL.Path = u.Path
+	c.Req.URL.RawPath = u.RawPath
+	if u.RawQuery != "" {
+		c.Req.URL.RawQuery = u.RawQuery
+		c.queryCache = nil
+	}
+	c.Path = u.Path
+	c.Params = nil
+	c.fullPath = ""
+	c.index = -1
+	c.engine.handleHTTPRequest(c)
+	// 原来的处理链不再继续执行，但转发成功时不能标记为中止，Logger 等中间件通过 IsAborted 判断请求是否失败
+	if !c.IsAborted() {
+		c.index = len(c.handlers)
+	}
+}
+
+// groupHandled 判断分组的中间件是否已经加入过处理链
+func (c *Context) groupHandled(group *RouterGroup) bool {
+	for _, g := range c.groups {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package tinyGin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRedirect(t *testing.T) {
+	r := New()
+	var status int
+	r.Use(func(c *Context) {
+		c.Next()
+		status = c.Writer.Status()
+	})
+	r.GET("/old", func(c *Context) {
+		c.Redirect(http.StatusMovedPermanently, "/new")
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/old", nil))
+	if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "/new" || status != http.StatusMovedPermanently {
+		t.Fatalf("unexpected redirect %d %q %d", w.Code, w.Header().Get("Location"), status)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("redirect with 200 should panic")
+		}
+	}()
+	c := newContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	c.Redirect(http.StatusOK, "/new")
+}
+
+func TestForward(t *testing.T) {
+	r := New()
+	calls, rootCalls, apiCalls := 0, 0, 0
+	aborted := false
+	r.Use(func(c *Context) {
+		rootCalls++
+		c.Next()
+		aborted = c.IsAborted()
+	})
+	old := r.Group("/old")
+	old.Use(func(c *Context) {
+		calls++
+		c.Forward("/new?name=amadeus")
+	})
+	old.GET("", func(c *Context) {
+		t.Fatal("handlers after Forward should not run")
+	})
+	r.GET("/new", func(c *Context) {
+		c.String(http.StatusOK, "hello %s from %s", c.Query("name"), c.Path)
+	})
+	api := r.Group("/api")
+	api.Use(func(c *Context) {
+		apiCalls++
+		c.Next()
+	})
+	api.GET("/user", func(c *Context) {
+		c.String(http.StatusOK, "user")
+	})
+	r.GET("/me", func(c *Context) {
+		c.Forward("/api/user")
+	})
+	r.GET("/loop", func(c *Context) {
+		c.Forward("/loop")
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/old", nil))
+	if w.Body.String() != "hello amadeus from /new" || calls != 1 {
+		t.Fatalf("unexpected body %q", w.Body.String())
+	}
+	if aborted {
+		t.Fatal("a successful forward should not mark the Context as aborted")
+	}
+	// 根分组的中间件已经执行过，只执行目标路由所在分组的中间件
+	rootCalls = 0
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/me", nil))
+	if w.Body.String() != "user" || rootCalls != 1 || apiCalls != 1 {
+		t.Fatalf("unexpected middleware calls root=%d api=%d body=%q", rootCalls, apiCalls, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/loop", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("forward loop should fail, got %d", w.Code)
+	}
+}
@@ -0,0 +1,31 @@
+package render
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+)
+
+// HTML 使用已加载的模板渲染页面
+type HTML struct {
+	Template *template.Template
+	Name     string
+	Data     interface{}
+}
+
+var htmlContentType = []string{"text/html; charset=utf-8"}
+
+func (r HTML) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	if r.Template == nil {
+		return errors.New("render: html templates are not loaded")
+	}
+	if r.Name == "" {
+		return r.Template.Execute(w, r.Data)
+	}
+	return r.Template.ExecuteTemplate(w, r.Name, r.Data)
+}
+
+func (r HTML) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, htmlContentType)
+}
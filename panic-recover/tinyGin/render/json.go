@@ -0,0 +1,174 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+)
+
+// JSON 普通的 JSON 响应，<、>、& 会被转义
+type JSON struct {
+	Data interface{}
+}
+
+// IndentedJSON 带缩进的 JSON，便于直接阅读
+type IndentedJSON struct {
+	Data interface{}
+}
+
+// SecureJSON 当 Data 序列化后是一个数组时，在前面加上 Prefix(例如 while(1);)，防止 JSON 劫持
+type SecureJSON struct {
+	Prefix string
+	Data   interface{}
+}
+
+// JSONP 将 JSON 包装成对 Callback 的调用，Callback 必须是合法的 JavaScript 标识符
+type JSONP struct {
+	Callback string
+	Data     interface{}
+}
+
+// PureJSON 不转义 HTML 字符的 JSON
+type PureJSON struct {
+	Data interface{}
+}
+
+// AsciiJSON 将非 ASCII 字符转义成 \uXXXX 的 JSON
+type AsciiJSON struct {
+	Data interface{}
+}
+
+var (
+	jsonContentType      = []string{"application/json; charset=utf-8"}
+	jsonpContentType     = []string{"application/javascript; charset=utf-8"}
+	jsonASCIIContentType = []string{"application/json"}
+)
+
+// ErrInvalidCallback JSONP 的 callback 不是合法的 JavaScript 标识符
+var ErrInvalidCallback = errors.New("render: invalid JSONP callback")
+
+// callbackRegexp 允许 foo、foo.bar、$foo_1 这样的形式
+var callbackRegexp = regexp.MustCompile(`^[a-zA-Z_$][0-9a-zA-Z_$]*(\.[a-zA-Z_$][0-9a-zA-Z_$]*)*$`)
+
+// ValidCallback 判断 callback 是否可以安全地用作 JSONP 的函数名
+func ValidCallback(callback string) bool {
+	return len(callback) <= 128 && callbackRegexp.MatchString(callback)
+}
+
+func (r JSON) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	// 先完整序列化再写出，避免序列化失败时只写了一半的响应体
+	data, err := json.Marshal(r.Data)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func (r JSON) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, jsonContentType)
+}
+
+func (r IndentedJSON) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	data, err := json.MarshalIndent(r.Data, "", "    ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func (r IndentedJSON) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, jsonContentType)
+}
+
+func (r SecureJSON) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	data, err := json.Marshal(r.Data)
+	if err != nil {
+		return err
+	}
+	// 只有数组形式的 JSON 才可能被 <script> 标签劫持
+	if bytes.HasPrefix(data, []byte("[")) && bytes.HasSuffix(data, []byte("]")) {
+		if _, err = w.Write([]byte(r.Prefix)); err != nil {
+			return err
+		}
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func (r SecureJSON) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, jsonContentType)
+}
+
+func (r JSONP) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	data, err := json.Marshal(r.Data)
+	if err != nil {
+		return err
+	}
+	if r.Callback == "" {
+		_, err = w.Write(data)
+		return err
+	}
+	if !ValidCallback(r.Callback) {
+		return ErrInvalidCallback
+	}
+	// 开头的 /**/ 用来防御 Rosetta Flash 之类的攻击
+	_, err = fmt.Fprintf(w, "/**/%s(%s);", r.Callback, data)
+	return err
+}
+
+func (r JSONP) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, jsonpContentType)
+}
+
+func (r PureJSON) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(r.Data); err != nil {
+		return err
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func (r PureJSON) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, jsonContentType)
+}
+
+func (r AsciiJSON) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	data, err := json.Marshal(r.Data)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	for _, ch := range string(data) {
+		if ch < 128 {
+			buf.WriteRune(ch)
+			continue
+		}
+		// 超出基本平面的字符需要拆成 UTF-16 代理对
+		if ch > 0xFFFF {
+			ch -= 0x10000
+			fmt.Fprintf(&buf, "\\u%04x\\u%04x", 0xD800+(ch>>10), 0xDC00+(ch&0x3FF))
+			continue
+		}
+		fmt.Fprintf(&buf, "\\u%04x", ch)
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+func (r AsciiJSON) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, jsonASCIIContentType)
+}
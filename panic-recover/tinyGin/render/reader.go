@@ -0,0 +1,38 @@
+package render
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// Reader 将 Reader 中的内容以流的方式写出，不需要先全部读入内存
+// ContentLength 小于 0 时表示长度未知，不设置 Content-Length
+type Reader struct {
+	ContentType   string
+	ContentLength int64
+	Reader        io.Reader
+	Headers       map[string]string
+}
+
+func (r Reader) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	_, err := io.Copy(w, r.Reader)
+	return err
+}
+
+// WriteContentType 除了 Content-Type，还会写入 Content-Length 和额外的 Header
+// 这些 Header 必须在写入状态码之前设置才会生效
+func (r Reader) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, []string{r.ContentType})
+	header := w.Header()
+	if r.ContentLength >= 0 {
+		header.Set("Content-Length", strconv.FormatInt(r.ContentLength, 10))
+	}
+	// 已经存在的 Header 不会被覆盖
+	for k, v := range r.Headers {
+		if header.Get(k) == "" {
+			header.Set(k, v)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package render
+
+import "net/http"
+
+/*
+render 负责把数据写进 http.ResponseWriter。
+之前 Context 的 String/Json/Data/HTML 各自设置 Header 再写 Body，每增加一种响应格式都要复制一遍同样的逻辑。
+现在把"如何写出某种格式"抽象成 Render 接口，Context 只需要调用 c.Render(code, r)。
+*/
+
+// Render 所有响应格式都需要实现的接口
+type Render interface {
+	// Render 写入响应体
+	Render(w http.ResponseWriter) error
+	// WriteContentType 写入对应的 Content-Type
+	WriteContentType(w http.ResponseWriter)
+}
+
+// 确保内置的类型都实现了 Render 接口
+var (
+	_ Render = JSON{}
+	_ Render = IndentedJSON{}
+	_ Render = SecureJSON{}
+	_ Render = JSONP{}
+	_ Render = PureJSON{}
+	_ Render = AsciiJSON{}
+	_ Render = XML{}
+	_ Render = YAML{}
+	_ Render = String{}
+	_ Render = Data{}
+	_ Render = HTML{}
+	_ Render = Reader{}
+	_ Render = SSEvent{}
+)
+
+// writeContentType 只在还没有设置 Content-Type 时写入，允许用户提前自定义
+func writeContentType(w http.ResponseWriter, value []string) {
+	header := w.Header()
+	if val := header["Content-Type"]; len(val) == 0 {
+		header["Content-Type"] = value
+	}
+}
@@ -0,0 +1,61 @@
+package render
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestYAML(t *testing.T) {
+	type item struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+	}
+	data := map[string]interface{}{
+		"count": 2,
+		"empty": []int{},
+		"items": []item{{Name: "a", Tags: []string{"x", "true"}}, {Name: "b: c"}},
+	}
+	got, err := MarshalYAML(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `count: 2
+empty: []
+items:
+  - name: a
+    tags:
+      - x
+      - "true"
+  - name: "b: c"
+    tags: null
+`
+	if string(got) != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestJSONVariants(t *testing.T) {
+	cases := []struct {
+		r    Render
+		want string
+	}{
+		{JSON{Data: map[string]string{"a": "<b>"}}, `{"a":"\u003cb\u003e"}`},
+		{PureJSON{Data: map[string]string{"a": "<b>"}}, "{\"a\":\"<b>\"}\n"},
+		{AsciiJSON{Data: "中😀"}, `"\u4e2d\ud83d\ude00"`},
+		{SecureJSON{Prefix: "while(1);", Data: []int{1}}, `while(1);[1]`},
+		{SecureJSON{Prefix: "while(1);", Data: map[string]int{"a": 1}}, `{"a":1}`},
+		{JSONP{Callback: "cb.fn", Data: 1}, `/**/cb.fn(1);`},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		if err := tc.r.Render(w); err != nil {
+			t.Fatal(err)
+		}
+		if w.Body.String() != tc.want {
+			t.Errorf("%T: got %q, want %q", tc.r, w.Body.String(), tc.want)
+		}
+	}
+	if err := (JSONP{Callback: "alert(1)//", Data: 1}).Render(httptest.NewRecorder()); err != ErrInvalidCallback {
+		t.Fatalf("expected ErrInvalidCallback, got %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package render
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+/*
+Server-Sent Events 的编码，格式见 https://html.spec.whatwg.org/multipage/server-sent-events.html
+每个事件由若干行 "字段: 值" 组成，以一个空行结束，例如
+	id: 42
+	event: message
+	retry: 3000
+	data: first line
+	data: second line
+*/
+
+// SSEvent 一个 Server-Sent Event
+// Data 是字符串或 []byte 时原样输出，多行内容会拆成多个 data 行；其他类型序列化为 JSON
+type SSEvent struct {
+	Event string
+	ID    string
+	Retry uint // 断线重连的等待时间(毫秒)，为 0 时不输出
+	Data  interface{}
+}
+
+var sseContentType = []string{"text/event-stream"}
+
+func (r SSEvent) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	return EncodeSSE(w, r)
+}
+
+func (r SSEvent) WriteContentType(w http.ResponseWriter) {
+	header := w.Header()
+	header["Content-Type"] = sseContentType
+	if _, exist := header["Cache-Control"]; !exist {
+		header.Set("Cache-Control", "no-cache")
+	}
+	// 关闭 nginx 的响应缓冲，否则事件会被攒到一起才发送
+	header.Set("X-Accel-Buffering", "no")
+}
+
+// EncodeSSE 将 event 按照 SSE 格式写入 w
+func EncodeSSE(w io.Writer, event SSEvent) error {
+	var b strings.Builder
+	if event.ID != "" {
+		b.WriteString("id: " + singleLine(event.ID) + "\n")
+	}
+	if event.Event != "" {
+		b.WriteString("event: " + singleLine(event.Event) + "\n")
+	}
+	if event.Retry > 0 {
+		b.WriteString("retry: " + strconv.FormatUint(uint64(event.Retry), 10) + "\n")
+	}
+	data, err := sseData(event.Data)
+	if err != nil {
+		return err
+	}
+	// 统一换行符之后按行拆分，每一行都需要 data: 前缀
+	data = strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(data)
+	for _, line := range strings.Split(data, "\n") {
+		b.WriteString("data: " + line + "\n")
+	}
+	b.WriteString("\n")
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+func sseData(data interface{}) (string, error) {
+	switch v := data.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	}
+	encoded, err := json.Marshal(data)
+	return string(encoded), err
+}
+
+// singleLine id 和 event 字段中不能出现换行，否则会被解析成新的字段
+func singleLine(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
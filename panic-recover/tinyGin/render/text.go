@@ -0,0 +1,46 @@
+package render
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// String 纯文本响应，Data 不为空时按 Format 格式化
+type String struct {
+	Format string
+	Data   []interface{}
+}
+
+// Data 原样写出的字节数据，ContentType 为空时不设置 Content-Type
+type Data struct {
+	ContentType string
+	Data        []byte
+}
+
+var plainContentType = []string{"text/plain; charset=utf-8"}
+
+func (r String) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	if len(r.Data) > 0 {
+		_, err := fmt.Fprintf(w, r.Format, r.Data...)
+		return err
+	}
+	_, err := w.Write([]byte(r.Format))
+	return err
+}
+
+func (r String) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, plainContentType)
+}
+
+func (r Data) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	_, err := w.Write(r.Data)
+	return err
+}
+
+func (r Data) WriteContentType(w http.ResponseWriter) {
+	if r.ContentType != "" {
+		writeContentType(w, []string{r.ContentType})
+	}
+}
@@ -0,0 +1,27 @@
+package render
+
+import (
+	"encoding/xml"
+	"net/http"
+)
+
+// XML 使用 encoding/xml 序列化的响应
+type XML struct {
+	Data interface{}
+}
+
+var xmlContentType = []string{"application/xml; charset=utf-8"}
+
+func (r XML) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	data, err := xml.Marshal(r.Data)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func (r XML) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, xmlContentType)
+}
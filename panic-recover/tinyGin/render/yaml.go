@@ -0,0 +1,204 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+/*
+YAML 响应。为了不引入外部依赖，这里先用 encoding/json 序列化，
+再按 token 顺序把 JSON 转换成 YAML，这样结构体字段的顺序和 json tag 都能保留下来。
+*/
+
+// YAML 使用 json tag 序列化的 YAML 响应
+type YAML struct {
+	Data interface{}
+}
+
+var yamlContentType = []string{"application/yaml; charset=utf-8"}
+
+func (r YAML) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	data, err := MarshalYAML(r.Data)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func (r YAML) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, yamlContentType)
+}
+
+// MarshalYAML 将 obj 序列化为 YAML
+func MarshalYAML(obj interface{}) ([]byte, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	v, err := decodeOrdered(decoder)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	writeYAML(&buf, v, 0, false)
+	return buf.Bytes(), nil
+}
+
+// yamlMap 保留 key 顺序的 map
+type yamlMap []yamlPair
+
+type yamlPair struct {
+	key   string
+	value interface{}
+}
+
+// decodeOrdered 读取一个完整的 JSON 值，对象会被解析成 yamlMap 以保留 key 的顺序
+func decodeOrdered(decoder *json.Decoder) (interface{}, error) {
+	tok, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			m := yamlMap{}
+			for decoder.More() {
+				keyTok, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				key, ok := keyTok.(string)
+				if !ok {
+					return nil, errors.New("render: invalid object key")
+				}
+				value, err := decodeOrdered(decoder)
+				if err != nil {
+					return nil, err
+				}
+				m = append(m, yamlPair{key: key, value: value})
+			}
+			_, err = decoder.Token() // }
+			return m, err
+		case '[':
+			list := []interface{}{}
+			for decoder.More() {
+				value, err := decodeOrdered(decoder)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, value)
+			}
+			_, err = decoder.Token() // ]
+			return list, err
+		}
+		return nil, errors.New("render: unexpected delimiter")
+	default:
+		return tok, nil
+	}
+}
+
+// writeYAML 以块格式写出 v，inline 表示当前行已经写了 "- "，第一个 key 不需要再缩进
+func writeYAML(buf *bytes.Buffer, v interface{}, indent int, inline bool) {
+	pad := strings.Repeat(" ", indent)
+	switch t := v.(type) {
+	case yamlMap:
+		if len(t) == 0 {
+			buf.WriteString("{}\n")
+			return
+		}
+		for i, pair := range t {
+			if i > 0 || !inline {
+				buf.WriteString(pad)
+			}
+			buf.WriteString(yamlScalar(pair.key))
+			buf.WriteString(":")
+			if isBlock(pair.value) {
+				buf.WriteString("\n")
+			} else {
+				buf.WriteString(" ")
+			}
+			writeYAML(buf, pair.value, indent+2, false)
+		}
+	case []interface{}:
+		if len(t) == 0 {
+			buf.WriteString("[]\n")
+			return
+		}
+		for i, item := range t {
+			if i > 0 || !inline {
+				buf.WriteString(pad)
+			}
+			// 数组中的 map 第一个 key 直接跟在 "- " 后面，嵌套的数组换行写出
+			_, isMap := item.(yamlMap)
+			if isBlock(item) && !isMap {
+				buf.WriteString("-\n")
+				writeYAML(buf, item, indent+2, false)
+				continue
+			}
+			buf.WriteString("- ")
+			writeYAML(buf, item, indent+2, isMap)
+		}
+	case nil:
+		buf.WriteString("null\n")
+	case bool:
+		buf.WriteString(strconv.FormatBool(t) + "\n")
+	case json.Number:
+		buf.WriteString(t.String() + "\n")
+	case string:
+		buf.WriteString(yamlScalar(t) + "\n")
+	}
+}
+
+// isBlock 非空的 map 和数组需要换行后以块格式写出
+func isBlock(v interface{}) bool {
+	switch t := v.(type) {
+	case yamlMap:
+		return len(t) > 0
+	case []interface{}:
+		return len(t) > 0
+	}
+	return false
+}
+
+// yamlScalar 字符串可能被 YAML 解析成其他类型或者包含特殊字符时，使用双引号
+// JSON 的字符串转义规则同样适用于 YAML 的双引号字符串
+func yamlScalar(s string) string {
+	if needsQuote(s) {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+func needsQuote(s string) bool {
+	if s == "" || strings.TrimSpace(s) != s {
+		return true
+	}
+	switch strings.ToLower(s) {
+	case "null", "~", "true", "false", "yes", "no", "on", "off", "y", "n":
+		return true
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return true
+	}
+	if strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") {
+		return true
+	}
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return true
+	}
+	for _, r := range s {
+		if r < 0x20 || r == 0x7f {
+			return true
+		}
+	}
+	return false
+}
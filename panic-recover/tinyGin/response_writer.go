@@ -0,0 +1,141 @@
+package tinyGin
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+)
+
+/*
+ResponseWriter 对 http.ResponseWriter 做了一层包装。
+直接使用 http.ResponseWriter 时，WriteHeader 会立刻把状态码发送出去，
+之后再调用 WriteHeader 只会得到 "superfluous WriteHeader" 的警告，而且框架也无从得知最终的状态码和响应大小。
+包装之后，WriteHeader 只记录状态码，直到第一次写入响应体(或者请求处理结束)时才真正发送，
+Logger 等中间件可以通过 Status()、Size() 拿到准确的结果。
+*/
+
+const (
+	noWritten     = -1
+	defaultStatus = http.StatusOK
+)
+
+// ResponseWriter Context.Writer 的类型
+type ResponseWriter interface {
+	http.ResponseWriter
+	http.Hijacker
+	http.Flusher
+	http.CloseNotifier
+
+	// Status 返回当前响应的状态码
+	Status() int
+	// Size 返回已经写入的响应体字节数，还没有写入 Header 时为 -1
+	Size() int
+	// WriteString 写入字符串
+	WriteString(string) (int, error)
+	// Written 是否已经发送了 Header
+	Written() bool
+	// WriteHeaderNow 立刻发送 Header
+	WriteHeaderNow()
+	// Pusher 底层连接支持 HTTP/2 Server Push 时返回 http.Pusher，否则返回 nil
+	Pusher() http.Pusher
+}
+
+type responseWriter struct {
+	http.ResponseWriter
+	size   int
+	status int
+}
+
+var _ ResponseWriter = &responseWriter{}
+
+func (w *responseWriter) reset(writer http.ResponseWriter) {
+	w.ResponseWriter = writer
+	w.size = noWritten
+	w.status = defaultStatus
+}
+
+// Unwrap 供 http.ResponseController 获取底层的 http.ResponseWriter
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// WriteHeader 只记录状态码，在第一次写入时才真正发送
+func (w *responseWriter) WriteHeader(code int) {
+	if code > 0 && w.status != code {
+		if w.Written() {
+			log.Printf("[WARNING] Headers were already written. Wanted to override status code %d with %d", w.status, code)
+			return
+		}
+		w.status = code
+	}
+}
+
+func (w *responseWriter) WriteHeaderNow() {
+	if !w.Written() {
+		w.size = 0
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+}
+
+func (w *responseWriter) Write(data []byte) (n int, err error) {
+	w.WriteHeaderNow()
+	n, err = w.ResponseWriter.Write(data)
+	w.size += n
+	return
+}
+
+func (w *responseWriter) WriteString(s string) (n int, err error) {
+	w.WriteHeaderNow()
+	n, err = io.WriteString(w.ResponseWriter, s)
+	w.size += n
+	return
+}
+
+func (w *responseWriter) Status() int {
+	return w.status
+}
+
+func (w *responseWriter) Size() int {
+	return w.size
+}
+
+func (w *responseWriter) Written() bool {
+	return w.size != noWritten
+}
+
+// Hijack 接管底层连接，之后框架不会再写入任何内容
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("tinyGin: the ResponseWriter doesn't support hijacking")
+	}
+	if w.size < 0 {
+		w.size = 0
+	}
+	return hijacker.Hijack()
+}
+
+// CloseNotify 底层不支持时返回一个永远不会收到消息的 channel
+func (w *responseWriter) CloseNotify() <-chan bool {
+	if notifier, ok := w.ResponseWriter.(http.CloseNotifier); ok {
+		return notifier.CloseNotify()
+	}
+	return make(chan bool)
+}
+
+func (w *responseWriter) Flush() {
+	w.WriteHeaderNow()
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *responseWriter) Pusher() http.Pusher {
+	if pusher, ok := w.ResponseWriter.(http.Pusher); ok {
+		return pusher
+	}
+	return nil
+}
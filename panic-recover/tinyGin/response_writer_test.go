@@ -0,0 +1,57 @@
+package tinyGin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResponseWriter(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := &responseWriter{}
+	w.reset(rec)
+	if w.Written() || w.Size() != -1 || w.Status() != http.StatusOK {
+		t.Fatal("unexpected initial state")
+	}
+	w.WriteHeader(http.StatusCreated)
+	if w.Written() {
+		t.Fatal("WriteHeader should not send the header immediately")
+	}
+	w.WriteString("hello")
+	w.WriteHeader(http.StatusInternalServerError)
+	if rec.Code != http.StatusCreated || w.Status() != http.StatusCreated {
+		t.Fatalf("status should stay 201, got %d/%d", rec.Code, w.Status())
+	}
+	if w.Size() != 5 {
+		t.Fatalf("expected size 5, got %d", w.Size())
+	}
+}
+
+func TestStatusWithoutBody(t *testing.T) {
+	r := New()
+	r.GET("/empty", func(c *Context) {
+		c.Status(http.StatusAccepted)
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/empty", nil))
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", w.Code)
+	}
+}
+
+func TestDeprecatedStatusCode(t *testing.T) {
+	r := New()
+	var afterStatus, afterWrite int
+	r.GET("/status", func(c *Context) {
+		c.Status(http.StatusCreated)
+		afterStatus = c.StatusCode
+		c.Writer.WriteString("ok")
+		// 响应头已经发出，状态码不会再变化，StatusCode 与 Writer 保持一致
+		c.Status(http.StatusInternalServerError)
+		afterWrite = c.StatusCode
+	})
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/status", nil))
+	if afterStatus != http.StatusCreated || afterWrite != http.StatusCreated {
+		t.Fatalf("StatusCode should mirror c.Writer.Status(), got %d/%d", afterStatus, afterWrite)
+	}
+}
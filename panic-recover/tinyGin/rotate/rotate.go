@@ -0,0 +1,336 @@
+package rotate
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+/*
+rotate 提供一个按大小和时间切分的日志文件 io.Writer，可以直接作为 Logger 中间件的输出：
+
+	w, _ := rotate.New("logs/access.log", rotate.Options{MaxSize: 100 << 20, Interval: 24 * time.Hour, MaxBackups: 7, Compress: true})
+	defer w.Close()
+	stop := w.ReopenOnSignal()
+	defer stop()
+	r.Use(tinyGin.LoggerWithConfig(tinyGin.LoggerConfig{Formatter: tinyGin.CombinedLogFormatter, Output: w}))
+
+切分时当前文件被重命名为 access-20060102T150405.000.log，然后重新创建 access.log，
+旧文件的压缩和清理在后台协程中进行，不会阻塞写日志。
+*/
+
+// backupTimeFormat 备份文件名中的时间格式，按字典序排序即按时间排序
+const backupTimeFormat = "20060102T150405.000"
+
+// Options 切分的条件和备份的处理方式
+type Options struct {
+	// MaxSize 文件超过这个字节数时切分，为 0 时不按大小切分
+	MaxSize int64
+	// Interval 每隔多长时间切分一次，为 0 时不按时间切分
+	// 切分时间按本地时间对齐：24h 在每天 0 点切分，1h 在每个整点切分，间隔不是整天时每天 0 点重新开始计算；
+	// 24h 以上的间隔按整天计算，不足一天的部分被忽略，例如 36h 等同于 24h
+	// 文件为空时不切分，空闲的服务不会每个间隔都留下一个空的备份
+	Interval time.Duration
+	// MaxBackups 最多保留的备份文件数，为 0 时全部保留
+	MaxBackups int
+	// Compress 是否用 gzip 压缩备份文件
+	Compress bool
+}
+
+// Writer 按大小和时间切分的日志文件，可以安全地被多个协程同时使用
+type Writer struct {
+	filename string
+	opts     Options
+
+	mu         sync.Mutex
+	file       *os.File
+	size       int64
+	nextRotate time.Time
+	closed     bool
+
+	millMu sync.Mutex // 保证同一时间只有一个协程在压缩和清理备份
+	wg     sync.WaitGroup
+}
+
+var _ io.WriteCloser = &Writer{}
+
+// now 方便测试时替换
+var now = time.Now
+
+// New 打开(或创建)filename，之后的写入追加在文件末尾
+func New(filename string, opts Options) (*Writer, error) {
+	w := &Writer{filename: filename, opts: opts}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write 写入日志，写入前如果满足切分条件则先切分，Close 之后返回 os.ErrClosed
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+	if w.shouldRotate(int64(len(p))) {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// shouldRotate 判断写入 incoming 字节之前是否需要切分
+// 到了切分时间但文件为空时不切分，只计算下一次切分的时间
+func (w *Writer) shouldRotate(incoming int64) bool {
+	if w.opts.MaxSize > 0 && w.size > 0 && w.size+incoming > w.opts.MaxSize {
+		return true
+	}
+	if w.opts.Interval <= 0 || now().Before(w.nextRotate) {
+		return false
+	}
+	if w.size == 0 {
+		w.nextRotate = nextBoundary(now(), w.opts.Interval)
+		return false
+	}
+	return true
+}
+
+// open 打开日志文件，并计算下一次按时间切分的时间
+func (w *Writer) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.filename), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(w.filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.file = f
+	w.size = info.Size()
+	if w.opts.Interval > 0 {
+		w.nextRotate = nextBoundary(now(), w.opts.Interval)
+	}
+	return nil
+}
+
+// nextBoundary 返回 t 之后的第一个切分时间，按 t 所在时区的 0 点对齐
+// time.Truncate 按 UTC 对齐，在东八区 24h 会变成每天 8 点切分，所以从当天的 0 点开始计算
+func nextBoundary(t time.Time, interval time.Duration) time.Time {
+	y, m, d := t.Date()
+	midnight := time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	const day = 24 * time.Hour
+	if interval >= day {
+		// 按日期计算，夏令时切换的那一天不是 24 小时
+		days := int(interval / day)
+		return time.Date(y, m, d+days, 0, 0, 0, 0, t.Location())
+	}
+	next := midnight.Add((t.Sub(midnight)/interval + 1) * interval)
+	if tomorrow := time.Date(y, m, d+1, 0, 0, 0, 0, t.Location()); next.After(tomorrow) {
+		next = tomorrow
+	}
+	return next
+}
+
+// rotate 把当前文件重命名为备份文件，然后重新打开一个新文件
+func (w *Writer) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	w.file = nil
+	backup := w.backupName(now())
+	if err := os.Rename(w.filename, backup); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		w.mill(backup)
+	}()
+	return nil
+}
+
+// Rotate 立刻切分
+func (w *Writer) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return os.ErrClosed
+	}
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return err
+		}
+	}
+	return w.rotate()
+}
+
+// Reopen 关闭并重新打开日志文件，用于配合外部的 logrotate：文件被移走之后，重新创建同名文件继续写入
+func (w *Writer) Reopen() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return os.ErrClosed
+	}
+	if w.file != nil {
+		if err := w.file.Close(); err != nil {
+			return err
+		}
+		w.file = nil
+	}
+	return w.open()
+}
+
+// ReopenOnSignal 收到信号时调用 Reopen，默认监听 SIGHUP，返回的函数用于停止监听
+func (w *Writer) ReopenOnSignal(sigs ...os.Signal) (stop func()) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGHUP}
+	}
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, sigs...)
+	go func() {
+		for {
+			select {
+			case <-ch:
+				w.Reopen()
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
+
+// Close 关闭日志文件，并等待后台的压缩和清理完成，之后的写入都会返回 os.ErrClosed
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	w.closed = true
+	var err error
+	if w.file != nil {
+		err = w.file.Close()
+		w.file = nil
+	}
+	w.mu.Unlock()
+	w.wg.Wait()
+	return err
+}
+
+// backupName 在文件名和扩展名之间插入时间，例如 access.log -> access-20060102T150405.000.log
+// 同一毫秒内多次切分时，时间依次加 1 毫秒，避免覆盖已有的备份(包括已经压缩的)
+func (w *Writer) backupName(t time.Time) string {
+	dir := filepath.Dir(w.filename)
+	base := filepath.Base(w.filename)
+	ext := filepath.Ext(base)
+	prefix := strings.TrimSuffix(base, ext)
+	for {
+		name := filepath.Join(dir, prefix+"-"+t.Format(backupTimeFormat)+ext)
+		if !exists(name) && !exists(name+".gz") {
+			return name
+		}
+		t = t.Add(time.Millisecond)
+	}
+}
+
+func exists(name string) bool {
+	_, err := os.Lstat(name)
+	return err == nil
+}
+
+// mill 压缩刚切分出来的备份文件，并删除多余的旧备份
+func (w *Writer) mill(backup string) {
+	w.millMu.Lock()
+	defer w.millMu.Unlock()
+	if w.opts.Compress {
+		if err := compressFile(backup); err == nil {
+			os.Remove(backup)
+		}
+	}
+	if w.opts.MaxBackups > 0 {
+		backups := w.backups()
+		for i := 0; i < len(backups)-w.opts.MaxBackups; i++ {
+			os.Remove(backups[i])
+		}
+	}
+}
+
+// backups 返回所有备份文件，按时间从旧到新排序
+func (w *Writer) backups() []string {
+	dir := filepath.Dir(w.filename)
+	base := filepath.Base(w.filename)
+	ext := filepath.Ext(base)
+	prefix := strings.TrimSuffix(base, ext) + "-"
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var backups []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimSuffix(name, ".gz"), ext)
+		stamp = strings.TrimPrefix(stamp, prefix)
+		if _, err := time.Parse(backupTimeFormat, stamp); err != nil {
+			continue
+		}
+		backups = append(backups, filepath.Join(dir, name))
+	}
+	sort.Strings(backups)
+	return backups
+}
+
+// compressFile 把 name 压缩为 name.gz
+func compressFile(name string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(name+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		gz.Close()
+		dst.Close()
+		os.Remove(name + ".gz")
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		dst.Close()
+		os.Remove(name + ".gz")
+		return err
+	}
+	return dst.Close()
+}
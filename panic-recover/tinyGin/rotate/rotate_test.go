@@ -0,0 +1,190 @@
+package rotate
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClock 每次调用前进 1 秒，保证备份文件名不重复
+func fakeClock(start time.Time) func() time.Time {
+	current := start
+	return func() time.Time {
+		current = current.Add(time.Second)
+		return current
+	}
+}
+
+func TestRotateBySize(t *testing.T) {
+	defer func(old func() time.Time) { now = old }(now)
+	now = fakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "access.log")
+	w, err := New(filename, Options{MaxSize: 10, MaxBackups: 2, Compress: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"line-1\n", "line-2\n", "line-3\n", "line-4\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	current, _ := os.ReadFile(filename)
+	if string(current) != "line-4\n" {
+		t.Fatalf("unexpected current file %q", current)
+	}
+	backups := w.backups()
+	if len(backups) != 2 {
+		t.Fatalf("expected 2 backups, got %v", backups)
+	}
+	// 最旧的 line-1 已经被清理，剩下的备份都被压缩
+	for i, want := range []string{"line-2\n", "line-3\n"} {
+		if !strings.HasSuffix(backups[i], ".log.gz") {
+			t.Fatalf("backup should be compressed: %s", backups[i])
+		}
+		f, _ := os.Open(backups[i])
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(gz)
+		f.Close()
+		if string(data) != want {
+			t.Fatalf("backup %d: got %q, want %q", i, data, want)
+		}
+	}
+}
+
+func TestRotateByInterval(t *testing.T) {
+	defer func(old func() time.Time) { now = old }(now)
+	current := time.Date(2024, 1, 1, 23, 59, 0, 0, time.UTC)
+	now = func() time.Time { return current }
+
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	w, err := New(filename, Options{Interval: 24 * time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	w.Write([]byte("day 1\n"))
+	current = current.Add(2 * time.Minute)
+	w.Write([]byte("day 2\n"))
+
+	backup := filepath.Join(dir, "app-20240102T000100.000.log")
+	if data, err := os.ReadFile(backup); err != nil || string(data) != "day 1\n" {
+		t.Fatalf("unexpected backup %q %v", data, err)
+	}
+}
+
+func TestIntervalSkipsEmptyFile(t *testing.T) {
+	defer func(old func() time.Time) { now = old }(now)
+	current := time.Date(2024, 1, 1, 23, 59, 0, 0, time.UTC)
+	now = func() time.Time { return current }
+
+	dir := t.TempDir()
+	w, err := New(filepath.Join(dir, "app.log"), Options{Interval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	// 空闲了几个小时，文件一直是空的，不应该留下空的备份
+	current = current.Add(3 * time.Hour)
+	w.Write([]byte("after idle\n"))
+	if backups := w.backups(); len(backups) != 0 {
+		t.Fatalf("empty file should not be rotated, got %v", backups)
+	}
+	current = current.Add(time.Hour)
+	w.Write([]byte("next hour\n"))
+	if backups := w.backups(); len(backups) != 1 {
+		t.Fatalf("expected 1 backup, got %v", backups)
+	}
+}
+
+func TestWriteAfterClose(t *testing.T) {
+	w, err := New(filepath.Join(t.TempDir(), "app.log"), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	if _, err := w.Write([]byte("late\n")); !errors.Is(err, os.ErrClosed) {
+		t.Fatalf("expected os.ErrClosed, got %v", err)
+	}
+	if err := w.Reopen(); !errors.Is(err, os.ErrClosed) {
+		t.Fatalf("Reopen after Close should fail, got %v", err)
+	}
+}
+
+func TestNextBoundary(t *testing.T) {
+	loc := time.FixedZone("UTC+8", 8*3600)
+	cases := []struct {
+		now      time.Time
+		interval time.Duration
+		want     time.Time
+	}{
+		// 按本地时间的 0 点切分，而不是 UTC 的 0 点(本地 8 点)
+		{time.Date(2024, 1, 1, 7, 30, 0, 0, loc), 24 * time.Hour, time.Date(2024, 1, 2, 0, 0, 0, 0, loc)},
+		{time.Date(2024, 1, 1, 23, 59, 0, 0, loc), 48 * time.Hour, time.Date(2024, 1, 3, 0, 0, 0, 0, loc)},
+		{time.Date(2024, 1, 1, 7, 30, 0, 0, loc), time.Hour, time.Date(2024, 1, 1, 8, 0, 0, 0, loc)},
+		// 不能整除一天的间隔在 0 点重新开始
+		{time.Date(2024, 1, 1, 22, 0, 0, 0, loc), 7 * time.Hour, time.Date(2024, 1, 2, 0, 0, 0, 0, loc)},
+	}
+	for _, tc := range cases {
+		if got := nextBoundary(tc.now, tc.interval); !got.Equal(tc.want) {
+			t.Errorf("nextBoundary(%v, %v) = %v, want %v", tc.now, tc.interval, got, tc.want)
+		}
+	}
+}
+
+func TestBackupNamesAreUnique(t *testing.T) {
+	defer func(old func() time.Time) { now = old }(now)
+	current := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return current }
+
+	dir := t.TempDir()
+	w, err := New(filepath.Join(dir, "app.log"), Options{Compress: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 同一毫秒内切分多次，每次的内容都要保留
+	for i := 0; i < 3; i++ {
+		w.Write([]byte("line\n"))
+		if err := w.Rotate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w.Close()
+	if backups := w.backups(); len(backups) != 3 {
+		t.Fatalf("expected 3 backups, got %v", backups)
+	}
+}
+
+func TestReopen(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	w, err := New(filename, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	w.Write([]byte("before\n"))
+	// 模拟外部的 logrotate 把文件移走
+	os.Rename(filename, filename+".1")
+	if err := w.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("after\n"))
+	if data, _ := os.ReadFile(filename); string(data) != "after\n" {
+		t.Fatalf("unexpected file after reopen %q", data)
+	}
+}
@@ -2,6 +2,7 @@ package tinyGin
 
 import (
 	"net/http"
+	"sort"
 	"strings"
 )
 
@@ -99,15 +100,48 @@ func (r *router) getRoute(method, path string) (*node, map[string]string) {
 func (r *router) handle(c *Context) {
 	// 获取节点和参数
 	n, params := r.getRoute(c.Method, c.Path)
+	var allowed []string
+	if n == nil && c.engine != nil && c.engine.HandleMethodNotAllowed {
+		// 只有开启了 405 才需要查找其他请求方式，默认返回 404 时不用遍历每一棵路由树
+		allowed = r.allowedMethods(c.Path)
+	}
 	if n != nil {
-		key := c.Method + "-" + c.Path
+		// handlers 是按照注册时的 pattern 保存的，动态路由需要用匹配到的节点的 pattern 查找
+		key := c.Method + "-" + n.pattern
 		// 在调用匹配到的handler前，将解析出来的路由参数赋值给了c.Params
 		c.Params = params
+		c.fullPath = n.pattern
 		c.handlers = append(c.handlers, r.handlers[key])
+	} else if len(allowed) > 0 {
+		// 路径存在但请求方法不匹配时返回 405，并通过 Allow 告诉客户端支持哪些方法
+		c.handlers = append(c.handlers, func(c *Context) {
+			c.SetHeader("Allow", strings.Join(allowed, ", "))
+			if c.useProblemDetails() {
+				c.Problem(NewProblem(http.StatusMethodNotAllowed, "method "+c.Method+" is not allowed for "+c.Path))
+				return
+			}
+			c.String(http.StatusMethodNotAllowed, "405 METHOD NOT ALLOWED: %s\n", c.Path)
+		})
 	} else {
 		c.handlers = append(c.handlers, func(c *Context) {
+			if c.useProblemDetails() {
+				c.Problem(NewProblem(http.StatusNotFound, "no route for "+c.Path))
+				return
+			}
 			c.String(http.StatusNotFound, "404 NOT FOUND: %s\n", c.Path)
 		})
 	}
 	c.Next()
 }
+
+// allowedMethods 返回能够匹配 path 的所有请求方式
+func (r *router) allowedMethods(path string) []string {
+	var allowed []string
+	for method := range r.roots {
+		if n, _ := r.getRoute(method, path); n != nil {
+			allowed = append(allowed, method)
+		}
+	}
+	sort.Strings(allowed)
+	return allowed
+}
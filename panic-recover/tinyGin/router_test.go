@@ -0,0 +1,32 @@
+package tinyGin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDynamicRoutes(t *testing.T) {
+	r := New()
+	r.GET("/hello", func(c *Context) {
+		c.String(http.StatusOK, "static")
+	})
+	r.GET("/hello/:name", func(c *Context) {
+		c.String(http.StatusOK, "hello %s", c.Param("name"))
+	})
+	r.GET("/assets/*filepath", func(c *Context) {
+		c.String(http.StatusOK, "file %s", c.Param("filepath"))
+	})
+	cases := map[string]string{
+		"/hello":              "static",
+		"/hello/amadeus":      "hello amadeus",
+		"/assets/css/app.css": "file css/app.css",
+	}
+	for path, want := range cases {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != http.StatusOK || w.Body.String() != want {
+			t.Errorf("%s: got %d %q, want %q", path, w.Code, w.Body.String(), want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package sessions
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/gob"
+	"errors"
+	"net/http"
+	"tinyGin"
+)
+
+// CookieStore 把会话数据直接保存在签名 Cookie 中，服务端不需要任何存储
+// 需要先通过 Engine.SetCookieSecrets 设置密钥，数据对客户端可见但无法篡改，不要保存敏感信息
+// Cookie 的大小限制在 4KB 左右，只适合保存少量数据
+type CookieStore struct {
+	Options tinyGin.CookieOptions
+}
+
+// NewCookieStore 创建 CookieStore，使用 DefaultOptions
+func NewCookieStore() *CookieStore {
+	return &CookieStore{Options: DefaultOptions}
+}
+
+// SessionOptions 返回新建会话时使用的 Cookie 设置
+func (s *CookieStore) SessionOptions() tinyGin.CookieOptions {
+	return s.Options
+}
+
+func (s *CookieStore) Load(c *tinyGin.Context, name string) (*Session, error) {
+	session := NewSession(s, name, s.Options)
+	value, err := c.SignedCookie(name)
+	if errors.Is(err, http.ErrNoCookie) {
+		return session, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&session.Values); err != nil {
+		return nil, err
+	}
+	session.IsNew = false
+	return session, nil
+}
+
+func (s *CookieStore) Save(c *tinyGin.Context, session *Session) error {
+	if session.Options.MaxAge < 0 {
+		c.SetCookie(session.Name(), "", session.Options)
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(session.Values); err != nil {
+		return err
+	}
+	return c.SetSignedCookie(session.Name(), base64.RawURLEncoding.EncodeToString(buf.Bytes()), session.Options)
+}
@@ -0,0 +1,178 @@
+package sessions
+
+import (
+	"encoding/gob"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+	"tinyGin"
+)
+
+// FilesystemStore 每个会话保存为 dir 下的一个文件，Cookie 中只保存会话 ID
+// 会话在 Options.MaxAge 秒之后过期，MaxAge 为 0 时不会过期，过期的文件在下一次读取时删除
+// 客户端不再访问的会话文件不会被自动删除，需要由调用方定期调用 Cleanup 清理，例如
+//
+//	go func() {
+//		for range time.Tick(time.Hour) {
+//			store.Cleanup()
+//		}
+//	}()
+type FilesystemStore struct {
+	Options tinyGin.CookieOptions
+	dir     string
+}
+
+type fileEntry struct {
+	Values  map[string]interface{}
+	Expires time.Time
+}
+
+// NewFilesystemStore 创建 FilesystemStore，dir 不存在时会自动创建
+func NewFilesystemStore(dir string) (*FilesystemStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FilesystemStore{Options: DefaultOptions, dir: dir}, nil
+}
+
+// staleTempAge 临时文件超过这个时间没有修改，说明写入时进程崩溃了，可以删除
+const staleTempAge = 10 * time.Minute
+
+// Cleanup 删除所有已经过期的会话文件，以及写入中断后留下的临时文件
+// 无法读取或者内容损坏的会话文件同样当作过期删除，不会影响其他文件的清理，返回遇到的第一个错误
+func (s *FilesystemStore) Cleanup() error {
+	var firstErr error
+	record := func(err error) {
+		if err != nil && !errors.Is(err, os.ErrNotExist) && firstErr == nil {
+			firstErr = err
+		}
+	}
+	now := time.Now()
+	files, err := filepath.Glob(filepath.Join(s.dir, "session_*"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		entry, err := readFileEntry(file)
+		if errors.Is(err, os.ErrNotExist) {
+			// 并发的请求已经删除了这个会话
+			continue
+		}
+		if err != nil {
+			record(err)
+			record(os.Remove(file))
+			continue
+		}
+		if !entry.Expires.IsZero() && now.After(entry.Expires) {
+			record(os.Remove(file))
+		}
+	}
+	temps, err := filepath.Glob(filepath.Join(s.dir, "tmp_*"))
+	if err != nil {
+		return err
+	}
+	for _, file := range temps {
+		// 正在写入的临时文件很快就会被重命名，只删除很久没有修改的
+		if info, err := os.Stat(file); err == nil && now.Sub(info.ModTime()) > staleTempAge {
+			record(os.Remove(file))
+		}
+	}
+	return firstErr
+}
+
+// readFileEntry 读取一个会话文件
+func readFileEntry(file string) (*fileEntry, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var entry fileEntry
+	if err := gob.NewDecoder(f).Decode(&entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (s *FilesystemStore) path(id string) string {
+	return filepath.Join(s.dir, "session_"+id)
+}
+
+// SessionOptions 返回新建会话时使用的 Cookie 设置
+func (s *FilesystemStore) SessionOptions() tinyGin.CookieOptions {
+	return s.Options
+}
+
+func (s *FilesystemStore) Load(c *tinyGin.Context, name string) (*Session, error) {
+	session := NewSession(s, name, s.Options)
+	id, err := c.Cookie(name)
+	// 会话 ID 会拼接成文件路径，格式不对的直接当作没有会话
+	if errors.Is(err, http.ErrNoCookie) || !validID(id) {
+		return session, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	entry, err := readFileEntry(s.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return session, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !entry.Expires.IsZero() && time.Now().After(entry.Expires) {
+		os.Remove(s.path(id))
+		return session, nil
+	}
+	if entry.Values != nil {
+		session.Values = entry.Values
+	}
+	session.ID = id
+	session.IsNew = false
+	return session, nil
+}
+
+func (s *FilesystemStore) Save(c *tinyGin.Context, session *Session) error {
+	if session.Options.MaxAge < 0 {
+		if session.ID != "" {
+			if err := os.Remove(s.path(session.ID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+		}
+		c.SetCookie(session.Name(), "", session.Options)
+		return nil
+	}
+	if session.ID == "" {
+		id, err := generateID()
+		if err != nil {
+			return err
+		}
+		session.ID = id
+	}
+	entry := fileEntry{Values: session.Values}
+	if session.Options.MaxAge > 0 {
+		entry.Expires = time.Now().Add(time.Duration(session.Options.MaxAge) * time.Second)
+	}
+	// 先写临时文件再重命名，避免并发读取到写了一半的文件
+	tmp, err := os.CreateTemp(s.dir, "tmp_")
+	if err != nil {
+		return err
+	}
+	if err := gob.NewEncoder(tmp).Encode(entry); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), s.path(session.ID)); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	c.SetCookie(session.Name(), session.ID, session.Options)
+	return nil
+}
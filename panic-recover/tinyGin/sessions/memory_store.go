@@ -0,0 +1,127 @@
+package sessions
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+	"tinyGin"
+)
+
+// MemoryStore 把会话保存在进程内存中，Cookie 中只保存会话 ID
+// 会话在 TTL 时间内没有保存就会过期，后台协程定期清理过期的会话
+// 进程重启后会话会丢失，多实例部署时需要使用共享的 Store
+type MemoryStore struct {
+	Options tinyGin.CookieOptions
+	ttl     time.Duration
+
+	mu       sync.Mutex
+	sessions map[string]memoryEntry
+	done     chan struct{}
+	once     sync.Once
+}
+
+type memoryEntry struct {
+	values  map[string]interface{}
+	expires time.Time
+}
+
+// NewMemoryStore 创建 MemoryStore，并启动清理过期会话的协程，不再使用时需要调用 Close
+func NewMemoryStore(ttl time.Duration) *MemoryStore {
+	s := &MemoryStore{
+		Options:  DefaultOptions,
+		ttl:      ttl,
+		sessions: make(map[string]memoryEntry),
+		done:     make(chan struct{}),
+	}
+	go s.sweep()
+	return s
+}
+
+// SessionOptions 返回新建会话时使用的 Cookie 设置
+func (s *MemoryStore) SessionOptions() tinyGin.CookieOptions {
+	return s.Options
+}
+
+func (s *MemoryStore) Load(c *tinyGin.Context, name string) (*Session, error) {
+	session := NewSession(s, name, s.Options)
+	id, err := c.Cookie(name)
+	if errors.Is(err, http.ErrNoCookie) || !validID(id) {
+		return session, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	entry, ok := s.sessions[id]
+	s.mu.Unlock()
+	if !ok || time.Now().After(entry.expires) {
+		return session, nil
+	}
+	// 复制一份，避免并发请求同时修改同一个 map
+	for k, v := range entry.values {
+		session.Values[k] = v
+	}
+	session.ID = id
+	session.IsNew = false
+	return session, nil
+}
+
+func (s *MemoryStore) Save(c *tinyGin.Context, session *Session) error {
+	if session.Options.MaxAge < 0 {
+		if session.ID != "" {
+			s.mu.Lock()
+			delete(s.sessions, session.ID)
+			s.mu.Unlock()
+		}
+		c.SetCookie(session.Name(), "", session.Options)
+		return nil
+	}
+	if session.ID == "" {
+		id, err := generateID()
+		if err != nil {
+			return err
+		}
+		session.ID = id
+	}
+	values := make(map[string]interface{}, len(session.Values))
+	for k, v := range session.Values {
+		values[k] = v
+	}
+	s.mu.Lock()
+	s.sessions[session.ID] = memoryEntry{values: values, expires: time.Now().Add(s.ttl)}
+	s.mu.Unlock()
+	c.SetCookie(session.Name(), session.ID, session.Options)
+	return nil
+}
+
+// Close 停止清理协程
+func (s *MemoryStore) Close() {
+	s.once.Do(func() {
+		close(s.done)
+	})
+}
+
+// sweep 每隔 TTL 的一半清理一次过期的会话
+func (s *MemoryStore) sweep() {
+	interval := s.ttl / 2
+	if interval < time.Second {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case now := <-ticker.C:
+			s.mu.Lock()
+			for id, entry := range s.sessions {
+				if now.After(entry.expires) {
+					delete(s.sessions, id)
+				}
+			}
+			s.mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package sessions
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/gob"
+	"net/http"
+	"tinyGin"
+)
+
+/*
+sessions 在 Context 的基础上提供服务端会话。
+Sessions 中间件在每个请求开始时从 Store 中加载会话，handler 通过 sessions.Default(c) 读写会话数据，
+修改之后需要调用 Save 才会持久化并写入 Cookie(必须在写入响应体之前调用)。
+
+	r.SetCookieSecrets([]byte("secret"))
+	r.Use(sessions.Sessions("tinyGin_session", sessions.NewCookieStore()))
+	r.GET("/login", func(c *tinyGin.Context) {
+		session := sessions.Default(c)
+		session.Set("user", "amadeus")
+		session.AddFlash("welcome back")
+		session.Save()
+	})
+
+会话数据使用 encoding/gob 序列化，保存自定义类型之前需要先调用 gob.Register。
+*/
+
+// DefaultKey 会话在 Context 中保存的 key
+const DefaultKey = "tinyGin/sessions"
+
+// flashKey 闪现消息保存在会话数据中的 key
+const flashKey = "_flash"
+
+func init() {
+	// 闪现消息以 []interface{} 的形式保存在会话数据中
+	gob.Register([]interface{}{})
+}
+
+// Store 会话的存储后端，实现这个接口就可以把会话保存到 Redis 等其他地方
+type Store interface {
+	// Load 读取本次请求的会话，请求中没有会话或者会话已经失效时返回一个新的会话
+	Load(c *tinyGin.Context, name string) (*Session, error)
+	// Save 持久化会话并写入 Cookie，Options.MaxAge 小于 0 时删除会话
+	Save(c *tinyGin.Context, s *Session) error
+}
+
+// OptionsStore 可以提供 Cookie 设置的 Store
+// Sessions 中间件在会话无法加载时会新建一个会话，实现这个接口后新会话同样使用 Store 的设置而不是 DefaultOptions
+type OptionsStore interface {
+	Store
+	SessionOptions() tinyGin.CookieOptions
+}
+
+// DefaultOptions 会话 Cookie 的默认设置，有效期 7 天
+var DefaultOptions = tinyGin.CookieOptions{
+	Path:     "/",
+	MaxAge:   7 * 24 * 3600,
+	HttpOnly: true,
+	SameSite: http.SameSiteLaxMode,
+}
+
+// Session 一次请求中的会话
+type Session struct {
+	ID      string                 // 会话 ID，基于 Cookie 存储数据的 Store 不使用
+	Values  map[string]interface{} // 会话数据
+	Options tinyGin.CookieOptions  // 写入 Cookie 时使用的设置
+	IsNew   bool                   // 是否是本次请求新建的会话
+
+	name  string
+	store Store
+	c     *tinyGin.Context
+}
+
+// NewSession 创建一个新的会话，供 Store 的实现使用
+func NewSession(store Store, name string, options tinyGin.CookieOptions) *Session {
+	return &Session{
+		Values:  make(map[string]interface{}),
+		Options: options,
+		IsNew:   true,
+		name:    name,
+		store:   store,
+	}
+}
+
+// Name 会话 Cookie 的名字
+func (s *Session) Name() string {
+	return s.name
+}
+
+// Get 返回会话中 key 对应的值，不存在时返回 nil
+func (s *Session) Get(key string) interface{} {
+	return s.Values[key]
+}
+
+// Set 设置会话中的值
+func (s *Session) Set(key string, value interface{}) {
+	s.Values[key] = value
+}
+
+// Delete 删除会话中的值
+func (s *Session) Delete(key string) {
+	delete(s.Values, key)
+}
+
+// Clear 删除会话中的所有值
+func (s *Session) Clear() {
+	for key := range s.Values {
+		delete(s.Values, key)
+	}
+}
+
+// AddFlash 添加一条闪现消息，闪现消息被读取一次之后就会被删除
+// 可以通过 vars 指定消息的分类，默认分类是 _flash
+func (s *Session) AddFlash(value interface{}, vars ...string) {
+	key := flashKey
+	if len(vars) > 0 {
+		key = vars[0]
+	}
+	var flashes []interface{}
+	if v, ok := s.Values[key].([]interface{}); ok {
+		flashes = v
+	}
+	s.Values[key] = append(flashes, value)
+}
+
+// Flashes 读取并删除闪现消息，需要调用 Save 才会真正从存储中删除
+func (s *Session) Flashes(vars ...string) []interface{} {
+	key := flashKey
+	if len(vars) > 0 {
+		key = vars[0]
+	}
+	flashes, _ := s.Values[key].([]interface{})
+	delete(s.Values, key)
+	return flashes
+}
+
+// Save 持久化会话，必须在写入响应体之前调用，否则 Set-Cookie 不会生效
+func (s *Session) Save() error {
+	return s.store.Save(s.c, s)
+}
+
+// Destroy 删除会话数据并让客户端的 Cookie 失效
+func (s *Session) Destroy() error {
+	s.Clear()
+	s.Options.MaxAge = -1
+	return s.Save()
+}
+
+// Sessions 加载会话的中间件，name 是保存会话的 Cookie 名
+// 会话无法加载时(例如 Cookie 被篡改)，错误会记录到 c.Errors 中，并使用一个新的会话
+func Sessions(name string, store Store) tinyGin.HandlerFunc {
+	return func(c *tinyGin.Context) {
+		s, err := store.Load(c, name)
+		if err != nil {
+			c.Error(err)
+			s = NewSession(store, name, storeOptions(store))
+		}
+		s.c = c
+		c.Set(DefaultKey, s)
+		c.Next()
+	}
+}
+
+// storeOptions 返回 store 配置的 Cookie 设置，没有实现 OptionsStore 的 Store 使用 DefaultOptions
+func storeOptions(store Store) tinyGin.CookieOptions {
+	if s, ok := store.(OptionsStore); ok {
+		return s.SessionOptions()
+	}
+	return DefaultOptions
+}
+
+// Default 返回 Sessions 中间件加载的会话
+func Default(c *tinyGin.Context) *Session {
+	return c.MustGet(DefaultKey).(*Session)
+}
+
+// generateID 生成 32 字节的随机会话 ID
+func generateID() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// validID 会话 ID 只能由 generateID 生成的字符组成，防止被用来构造文件路径
+func validID(id string) bool {
+	if len(id) != 43 {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
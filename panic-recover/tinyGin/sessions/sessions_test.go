@@ -0,0 +1,249 @@
+package sessions
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"tinyGin"
+)
+
+// newServer 注册写入、读取和销毁会话的路由
+func newServer(store Store) *tinyGin.Engine {
+	r := tinyGin.New()
+	r.SetCookieSecrets([]byte("secret"))
+	r.Use(Sessions("session", store))
+	r.GET("/login", func(c *tinyGin.Context) {
+		s := Default(c)
+		s.Set("user", "amadeus")
+		s.AddFlash("welcome")
+		if err := s.Save(); err != nil {
+			c.Fail(http.StatusInternalServerError, err.Error())
+			return
+		}
+		c.String(http.StatusOK, "ok")
+	})
+	r.GET("/me", func(c *tinyGin.Context) {
+		s := Default(c)
+		flashes := s.Flashes()
+		s.Save()
+		c.Json(http.StatusOK, tinyGin.H{"user": s.Get("user"), "flashes": len(flashes)})
+	})
+	r.GET("/logout", func(c *tinyGin.Context) {
+		Default(c).Destroy()
+		c.String(http.StatusOK, "bye")
+	})
+	return r
+}
+
+type client struct {
+	t       *testing.T
+	r       *tinyGin.Engine
+	cookies map[string]*http.Cookie
+}
+
+func (cl *client) get(path string) string {
+	req := httptest.NewRequest("GET", path, nil)
+	for _, cookie := range cl.cookies {
+		req.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	cl.r.ServeHTTP(w, req)
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.MaxAge < 0 {
+			delete(cl.cookies, cookie.Name)
+			continue
+		}
+		cl.cookies[cookie.Name] = cookie
+	}
+	return w.Body.String()
+}
+
+func testStore(t *testing.T, store Store) {
+	cl := &client{t: t, r: newServer(store), cookies: map[string]*http.Cookie{}}
+	if got := cl.get("/me"); got != `{"flashes":0,"user":null}` {
+		t.Fatalf("unexpected anonymous session %s", got)
+	}
+	cl.get("/login")
+	if got := cl.get("/me"); got != `{"flashes":1,"user":"amadeus"}` {
+		t.Fatalf("unexpected session %s", got)
+	}
+	// 闪现消息只能读取一次
+	if got := cl.get("/me"); got != `{"flashes":0,"user":"amadeus"}` {
+		t.Fatalf("flashes should be consumed, got %s", got)
+	}
+	cl.get("/logout")
+	if got := cl.get("/me"); got != `{"flashes":0,"user":null}` {
+		t.Fatalf("session should be destroyed, got %s", got)
+	}
+}
+
+func TestCookieStore(t *testing.T) {
+	testStore(t, NewCookieStore())
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore(time.Minute)
+	defer store.Close()
+	testStore(t, store)
+}
+
+func TestFilesystemStore(t *testing.T) {
+	store, err := NewFilesystemStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, store)
+}
+
+func TestMemoryStoreExpires(t *testing.T) {
+	store := NewMemoryStore(10 * time.Millisecond)
+	defer store.Close()
+	cl := &client{t: t, r: newServer(store), cookies: map[string]*http.Cookie{}}
+	cl.get("/login")
+	time.Sleep(20 * time.Millisecond)
+	if got := cl.get("/me"); got != `{"flashes":0,"user":null}` {
+		t.Fatalf("session should expire, got %s", got)
+	}
+}
+
+func TestFilesystemStoreCleanup(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFilesystemStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Options.MaxAge = 1
+	cl := &client{t: t, r: newServer(store), cookies: map[string]*http.Cookie{}}
+	cl.get("/login")
+	if files, _ := filepath.Glob(filepath.Join(dir, "session_*")); len(files) != 1 {
+		t.Fatalf("expected 1 session file, got %d", len(files))
+	}
+	if err := store.Cleanup(); err != nil {
+		t.Fatal(err)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "session_*")); len(files) != 1 {
+		t.Fatal("unexpired session should be kept")
+	}
+	time.Sleep(1100 * time.Millisecond)
+	if err := store.Cleanup(); err != nil {
+		t.Fatal(err)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "session_*")); len(files) != 0 {
+		t.Fatalf("expired session should be removed, got %d", len(files))
+	}
+}
+
+func TestFilesystemStoreCleanupCorruptFiles(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFilesystemStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 损坏的文件排在过期的文件前面，不能让清理中途停止
+	os.WriteFile(filepath.Join(dir, "session_a"), []byte("corrupt"), 0o600)
+	f, _ := os.Create(filepath.Join(dir, "session_b"))
+	gob.NewEncoder(f).Encode(fileEntry{Expires: time.Now().Add(-time.Hour)})
+	f.Close()
+	stale := filepath.Join(dir, "tmp_stale")
+	os.WriteFile(stale, nil, 0o600)
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(stale, old, old)
+	fresh := filepath.Join(dir, "tmp_fresh")
+	os.WriteFile(fresh, nil, 0o600)
+
+	if err := store.Cleanup(); err == nil {
+		t.Fatal("the decode error should be reported")
+	}
+	for _, name := range []string{"session_a", "session_b", "tmp_stale"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s should be removed", name)
+		}
+	}
+	if _, err := os.Stat(fresh); err != nil {
+		t.Error("a temp file being written should be kept")
+	}
+}
+
+func TestSessionsFallbackUsesStoreOptions(t *testing.T) {
+	store := NewCookieStore()
+	store.Options.Path = "/app"
+	r := tinyGin.New()
+	r.SetCookieSecrets([]byte("secret"))
+	r.Use(Sessions("session", store))
+	r.GET("/save", func(c *tinyGin.Context) {
+		Default(c).Save()
+	})
+	req := httptest.NewRequest("GET", "/save", nil)
+	// 签名不对的 Cookie 无法加载，中间件使用新的会话
+	req.AddCookie(&http.Cookie{Name: "session", Value: "tampered"})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Path != "/app" {
+		t.Fatalf("fallback session should use the store's Options, got %v", cookies)
+	}
+}
+
+// brokenStore 模拟一个加载总是失败的自定义 Store，例如 Redis 暂时不可用
+type brokenStore struct {
+	options tinyGin.CookieOptions
+}
+
+func (s *brokenStore) Load(c *tinyGin.Context, name string) (*Session, error) {
+	return nil, errors.New("store unavailable")
+}
+
+func (s *brokenStore) Save(c *tinyGin.Context, session *Session) error {
+	c.SetCookie(session.Name(), "id", session.Options)
+	return nil
+}
+
+func (s *brokenStore) SessionOptions() tinyGin.CookieOptions {
+	return s.options
+}
+
+func TestSessionsFallbackUsesCustomStoreOptions(t *testing.T) {
+	r := tinyGin.New()
+	r.Use(Sessions("session", &brokenStore{options: tinyGin.CookieOptions{Path: "/custom", Secure: true}}))
+	r.GET("/save", func(c *tinyGin.Context) {
+		Default(c).Save()
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/save", nil))
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Path != "/custom" || !cookies[0].Secure {
+		t.Fatalf("fallback session should use the custom store's options, got %v", cookies)
+	}
+}
+
+func TestForwardKeepsSession(t *testing.T) {
+	var logs bytes.Buffer
+	r := tinyGin.New()
+	r.SetCookieSecrets([]byte("secret"))
+	r.Use(tinyGin.LoggerWithWriter(&logs), Sessions("session", NewCookieStore()))
+	r.GET("/old", func(c *tinyGin.Context) {
+		Default(c).Set("user", "amadeus")
+		c.Forward("/new")
+	})
+	r.GET("/new", func(c *tinyGin.Context) {
+		// 转发之后仍然是同一个会话，Forward 之前的修改不会因为重新加载会话而丢失
+		s := Default(c)
+		s.Save()
+		c.String(http.StatusOK, "%v", s.Get("user"))
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/old", nil))
+	if w.Body.String() != "amadeus" || len(w.Result().Cookies()) != 1 {
+		t.Fatalf("session should survive Forward, got %q", w.Body.String())
+	}
+	if lines := strings.Count(logs.String(), "\n"); lines != 1 {
+		t.Fatalf("request should be logged once, got %d lines:\n%s", lines, logs.String())
+	}
+}
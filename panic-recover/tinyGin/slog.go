@@ -0,0 +1,172 @@
+package tinyGin
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"sort"
+	"time"
+)
+
+/*
+结构化访问日志：每个请求输出一条 log/slog 记录，方便日志系统按字段检索。
+每条记录都带有 request_id，优先使用客户端传入的 X-Request-ID(只接受不超过 128 个字母、数字和 . _ -)，没有或者不合法时自动生成，并写回响应头，
+handler 中通过 c.Logger() 输出的日志也会带上同一个 request_id，这样就能把同一个请求的所有日志关联起来。
+日志级别由状态码决定：5xx 为 Error，4xx 为 Warn，其余为 Info。Query 中 token、api_key 等敏感参数的值会被替换为 *。
+*/
+
+// RequestIDKey 请求 id 在 Context 中保存的 key
+const RequestIDKey = "tinyGin/requestID"
+
+// slogLoggerKey 带有 request_id 的 *slog.Logger 在 Context 中保存的 key
+const slogLoggerKey = "tinyGin/slogLogger"
+
+// 访问日志中使用的字段名
+const (
+	LogAttrRequestID = "request_id"
+	LogAttrMethod    = "method"
+	LogAttrPath      = "path"
+	LogAttrRoute     = "route"
+	LogAttrQuery     = "query"
+	LogAttrStatus    = "status"
+	LogAttrLatency   = "latency"
+	LogAttrClientIP  = "client_ip"
+	LogAttrUserAgent = "user_agent"
+	LogAttrBytesOut  = "bytes_out"
+	LogAttrErrors    = "errors"
+	LogAttrParams    = "params"
+)
+
+// SlogOptions SlogLogger 中间件的配置
+type SlogOptions struct {
+	// Message 日志记录的消息，默认为 request
+	Message string
+	// RequestIDHeader 读取和写回请求 id 的请求头，默认为 X-Request-ID
+	RequestIDHeader string
+	// GenerateRequestID 生成请求 id，默认为 16 字节随机数的十六进制
+	GenerateRequestID func() string
+	// SkipPaths 不记录日志的请求路径
+	SkipPaths []string
+	// Skip 返回 true 时不记录日志
+	Skip func(c *Context) bool
+}
+
+// SlogLogger 使用 logger 输出结构化访问日志的中间件，logger 为 nil 时使用 slog.Default()
+func SlogLogger(logger *slog.Logger, opts SlogOptions) HandlerFunc {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if opts.Message == "" {
+		opts.Message = "request"
+	}
+	if opts.RequestIDHeader == "" {
+		opts.RequestIDHeader = "X-Request-ID"
+	}
+	if opts.GenerateRequestID == nil {
+		opts.GenerateRequestID = generateRequestID
+	}
+	skip := make(map[string]struct{}, len(opts.SkipPaths))
+	for _, path := range opts.SkipPaths {
+		skip[path] = struct{}{}
+	}
+
+	return func(c *Context) {
+		start := time.Now()
+		path := c.Req.URL.Path
+		requestID := c.Req.Header.Get(opts.RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = opts.GenerateRequestID()
+		}
+		c.Writer.Header().Set(opts.RequestIDHeader, requestID)
+		c.Set(RequestIDKey, requestID)
+		c.Set(slogLoggerKey, logger.With(slog.String(LogAttrRequestID, requestID)))
+
+		c.Next()
+
+		if _, ok := skip[path]; ok || (opts.Skip != nil && opts.Skip(c)) {
+			return
+		}
+		status := c.Writer.Status()
+		attrs := []slog.Attr{
+			slog.String(LogAttrRequestID, requestID),
+			slog.String(LogAttrMethod, c.Req.Method),
+			slog.String(LogAttrPath, path),
+			slog.String(LogAttrRoute, c.FullPath()),
+			slog.Int(LogAttrStatus, status),
+			slog.Duration(LogAttrLatency, time.Since(start)),
+			slog.String(LogAttrClientIP, c.ClientIP()),
+			slog.String(LogAttrUserAgent, c.Req.UserAgent()),
+			slog.Int(LogAttrBytesOut, max(c.Writer.Size(), 0)),
+		}
+		if raw := c.Req.URL.RawQuery; raw != "" {
+			attrs = append(attrs, slog.String(LogAttrQuery, maskQuery(raw)))
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.Any(LogAttrErrors, c.Errors.Errors()))
+		}
+		logger.LogAttrs(c.Req.Context(), levelForStatus(status), opts.Message, attrs...)
+	}
+}
+
+// levelForStatus 根据状态码选择日志级别
+func levelForStatus(status int) slog.Level {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return slog.LevelError
+	case status >= http.StatusBadRequest:
+		return slog.LevelWarn
+	}
+	return slog.LevelInfo
+}
+
+// validRequestID 客户端传入的请求 id 会写入日志和响应头，只接受 1 到 128 个字母、数字和 . _ -
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		b := id[i]
+		if !('a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' || '0' <= b && b <= '9' || b == '.' || b == '_' || b == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+func generateRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Logger 返回本次请求的 *slog.Logger，带有 request_id、匹配到的路由和路由参数
+// 没有使用 SlogLogger 中间件时基于 slog.Default()
+func (c *Context) Logger() *slog.Logger {
+	logger := slog.Default()
+	if value, ok := c.Get(slogLoggerKey); ok {
+		logger = value.(*slog.Logger)
+	}
+	args := []any{slog.String(LogAttrRoute, c.FullPath())}
+	if len(c.Params) > 0 {
+		keys := make([]string, 0, len(c.Params))
+		for key := range c.Params {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		params := make([]any, len(keys))
+		for i, key := range keys {
+			params[i] = slog.String(key, c.Params[key])
+		}
+		args = append(args, slog.Group(LogAttrParams, params...))
+	}
+	return logger.With(args...)
+}
+
+// RequestID 返回 SlogLogger 中间件设置的请求 id
+func (c *Context) RequestID() string {
+	if value, ok := c.Get(RequestIDKey); ok {
+		return value.(string)
+	}
+	return ""
+}
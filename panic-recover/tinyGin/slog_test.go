@@ -0,0 +1,74 @@
+package tinyGin
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	r := New()
+	r.Use(SlogLogger(logger, SlogOptions{}))
+	r.GET("/users/:name", func(c *Context) {
+		c.Logger().Info("loading user")
+		c.String(http.StatusNotFound, "no such user")
+	})
+	req := httptest.NewRequest("GET", "/users/amadeus?full=1&api_key=secret", nil)
+	req.Header.Set("X-Request-ID", "abc123")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Header().Get("X-Request-ID") != "abc123" {
+		t.Fatal("request id should be echoed")
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 records, got %q", buf.String())
+	}
+	var handlerRecord, accessRecord map[string]interface{}
+	json.Unmarshal([]byte(lines[0]), &handlerRecord)
+	json.Unmarshal([]byte(lines[1]), &accessRecord)
+	if handlerRecord["request_id"] != "abc123" || handlerRecord["route"] != "/users/:name" ||
+		handlerRecord["params"].(map[string]interface{})["name"] != "amadeus" {
+		t.Fatalf("unexpected handler record %v", handlerRecord)
+	}
+	if accessRecord["level"] != "WARN" || accessRecord["status"] != float64(404) || accessRecord["request_id"] != "abc123" ||
+		accessRecord["query"] != "full=1&api_key=*" || accessRecord["bytes_out"] != float64(12) {
+		t.Fatalf("unexpected access record %v", accessRecord)
+	}
+}
+
+func TestSlogGeneratesRequestID(t *testing.T) {
+	r := New()
+	r.Use(SlogLogger(slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)), SlogOptions{}))
+	var id string
+	r.GET("/", func(c *Context) {
+		id = c.RequestID()
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if len(id) != 32 || w.Header().Get("X-Request-ID") != id {
+		t.Fatalf("unexpected generated id %q", id)
+	}
+}
+
+func TestSlogRejectsUnsafeRequestID(t *testing.T) {
+	r := New()
+	r.Use(SlogLogger(slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)), SlogOptions{}))
+	r.GET("/", func(c *Context) {})
+	for _, id := range []string{"abc 123", "a\nlevel=ERROR", "<script>", strings.Repeat("a", 129)} {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-Request-ID", id)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if got := w.Header().Get("X-Request-ID"); got == id || len(got) != 32 {
+			t.Errorf("%q should be replaced by a generated id, got %q", id, got)
+		}
+	}
+}
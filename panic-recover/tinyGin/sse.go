@@ -0,0 +1,53 @@
+package tinyGin
+
+import (
+	"io"
+	"tinyGin/render"
+)
+
+/*
+Server-Sent Events：服务端通过一个一直保持的 HTTP 响应不断向浏览器推送事件。
+	r.GET("/events", func(c *tinyGin.Context) {
+		c.Stream(func(w io.Writer) bool {
+			c.SSEvent("tick", time.Now())
+			time.Sleep(time.Second)
+			return true
+		})
+	})
+浏览器断线重连时会通过 Last-Event-ID 请求头带上最后收到的事件 id，可以据此从断点继续推送。
+*/
+
+// SSEvent 推送一个事件，name 为事件名，message 为事件数据
+func (c *Context) SSEvent(name string, message interface{}) {
+	c.WriteEvent(render.SSEvent{Event: name, Data: message})
+}
+
+// WriteEvent 推送一个完整的事件，可以设置 id 和 retry
+func (c *Context) WriteEvent(event render.SSEvent) {
+	// 状态码传 -1，不修改之前设置的状态码
+	c.Render(-1, event)
+}
+
+// LastEventID 返回客户端重连时带上的最后一个事件 id
+func (c *Context) LastEventID() string {
+	return c.Req.Header.Get("Last-Event-ID")
+}
+
+// Stream 不断调用 step 写入数据，每次调用之后立刻 Flush 把数据发送给客户端
+// step 返回 false 或者客户端断开连接时停止，客户端断开时返回 true
+func (c *Context) Stream(step func(w io.Writer) bool) bool {
+	w := c.Writer
+	clientGone := c.Req.Context().Done()
+	for {
+		select {
+		case <-clientGone:
+			return true
+		default:
+			keepOpen := step(w)
+			w.Flush()
+			if !keepOpen {
+				return false
+			}
+		}
+	}
+}
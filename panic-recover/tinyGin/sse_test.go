@@ -0,0 +1,58 @@
+package tinyGin
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"tinyGin/render"
+)
+
+func TestSSEStream(t *testing.T) {
+	r := New()
+	r.GET("/events", func(c *Context) {
+		start, _ := strconv.Atoi(c.LastEventID())
+		i := start
+		c.Stream(func(w io.Writer) bool {
+			i++
+			c.WriteEvent(render.SSEvent{ID: strconv.Itoa(i), Event: "tick", Data: "line1\nline2"})
+			return i < start+2
+		})
+		c.SSEvent("done", H{"count": i})
+	})
+	req := httptest.NewRequest("GET", "/events", nil)
+	req.Header.Set("Last-Event-ID", "5")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	want := "id: 6\nevent: tick\ndata: line1\ndata: line2\n\n" +
+		"id: 7\nevent: tick\ndata: line1\ndata: line2\n\n" +
+		"event: done\ndata: {\"count\":7}\n\n"
+	if w.Body.String() != want {
+		t.Fatalf("unexpected body:\n%s", w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" || !w.Flushed {
+		t.Fatalf("unexpected Content-Type %q or not flushed", ct)
+	}
+}
+
+func TestStreamStopsOnDisconnect(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+	c := newContext(httptest.NewRecorder(), req)
+	steps := 0
+	gone := c.Stream(func(w io.Writer) bool {
+		steps++
+		if steps == 3 {
+			cancel()
+		}
+		return true
+	})
+	if !gone || steps != 3 {
+		t.Fatalf("expected stream to stop after disconnect, gone=%v steps=%d", gone, steps)
+	}
+	if c.Writer.Status() != http.StatusOK {
+		t.Fatal("unexpected status")
+	}
+}
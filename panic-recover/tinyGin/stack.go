@@ -0,0 +1,195 @@
+package tinyGin
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+/*
+panic 的调用栈。
+为了让日志更容易阅读，调用栈中 runtime 和 tinyGin 自身(中间件链、路由分发)的帧会被过滤掉，只留下应用代码和标准库；
+设置了 RecoveryConfig.SourceLines 时，应用代码(主模块中的包)的每一帧还会附带出错行附近的源码，默认不显示，避免源码进入生产环境的日志。
+*/
+
+// maxStackDepth 不记录完整调用栈时最多保留的帧数
+const maxStackDepth = 32
+
+// stackFrame 调用栈中的一帧
+type stackFrame struct {
+	Function string
+	File     string
+	Line     int
+	// App 是否是应用代码，只有应用代码才附带源码
+	App    bool
+	Source []sourceLine
+}
+
+// sourceLine 一行源码
+type sourceLine struct {
+	Number  int
+	Text    string
+	Current bool // 是否是出错的那一行
+}
+
+// frameworkDir tinyGin 源码所在的目录，这个目录及子目录下除测试之外的文件都属于框架
+var frameworkDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return path.Dir(file)
+}()
+
+// mainModule 主模块的路径，用于判断一帧是否是应用代码
+var mainModule = func() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		return info.Main.Path
+	}
+	return ""
+}()
+
+// callers 获取调用栈，skip 的含义与 runtime.Callers 相同
+// full 为 false 时最多保留 maxStackDepth 帧；sourceLines 大于 0 时为应用代码的帧附带源码
+func callers(skip int, full bool, sourceLines int) []stackFrame {
+	return stackFrames(callerPCs(skip+1), full, sourceLines)
+}
+
+// callerPCs 返回调用栈的程序计数器，skip 的含义与 runtime.Callers 相同
+func callerPCs(skip int) []uintptr {
+	// Callers 用来返回调用栈的程序计数器，调用栈比缓冲区深时扩大缓冲区重新获取
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip+1, pcs)
+	for n == len(pcs) {
+		pcs = make([]uintptr, len(pcs)*2)
+		n = runtime.Callers(skip+1, pcs)
+	}
+	return pcs[:n]
+}
+
+// stackFrames 把程序计数器转换为调用栈，过滤掉 runtime 和 tinyGin 自身的帧
+func stackFrames(pcs []uintptr, full bool, sourceLines int) []stackFrame {
+	var stack []stackFrame
+	files := make(map[string][]string)
+	// CallersFrames 会展开内联的函数，比逐个调用 runtime.FuncForPC 更准确
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" && !hiddenFrame(frame) {
+			sf := stackFrame{
+				Function: frame.Function,
+				File:     frame.File,
+				Line:     frame.Line,
+				App:      isAppFunction(frame.Function),
+			}
+			if sf.App && sourceLines > 0 {
+				sf.Source = readSource(files, sf.File, sf.Line, sourceLines)
+			}
+			stack = append(stack, sf)
+			if !full && len(stack) == maxStackDepth {
+				break
+			}
+		}
+		if !more {
+			break
+		}
+	}
+	return stack
+}
+
+// hiddenFrame 判断一帧是否属于 runtime 或 tinyGin 自身，这些帧对定位问题没有帮助
+func hiddenFrame(frame runtime.Frame) bool {
+	if strings.HasPrefix(frame.Function, "runtime.") {
+		return true
+	}
+	if strings.HasSuffix(frame.File, "_test.go") {
+		return false
+	}
+	return path.Dir(frame.File) == frameworkDir || strings.HasPrefix(frame.File, frameworkDir+"/")
+}
+
+// isAppFunction 判断函数是否属于主模块，function 形如 example.com/app/handlers.(*User).Get
+func isAppFunction(function string) bool {
+	pkg := function
+	slash := strings.LastIndexByte(pkg, '/')
+	if dot := strings.IndexByte(pkg[slash+1:], '.'); dot >= 0 {
+		pkg = pkg[:slash+1+dot]
+	}
+	if pkg == "main" {
+		return true
+	}
+	return mainModule != "" && (pkg == mainModule || strings.HasPrefix(pkg, mainModule+"/"))
+}
+
+// readSource 读取 file 中第 line 行前后各 context 行，files 缓存已经读取过的文件
+func readSource(files map[string][]string, file string, line, context int) []sourceLine {
+	lines, ok := files[file]
+	if !ok {
+		data, err := os.ReadFile(file)
+		if err == nil {
+			lines = strings.Split(string(data), "\n")
+		}
+		files[file] = lines
+	}
+	if line < 1 || line > len(lines) {
+		return nil
+	}
+	start := max(line-context, 1)
+	end := min(line+context, len(lines))
+	source := make([]sourceLine, 0, end-start+1)
+	for i := start; i <= end; i++ {
+		source = append(source, sourceLine{Number: i, Text: lines[i-1], Current: i == line})
+	}
+	return source
+}
+
+// formatStack 将 panic 信息和调用栈格式化为日志
+func formatStack(message string, stack []stackFrame) string {
+	var str strings.Builder
+	str.WriteString(message + "\nTraceback:")
+	for _, frame := range stack {
+		fmt.Fprintf(&str, "\n\t%s\n\t\t%s:%d", frame.Function, frame.File, frame.Line)
+		for _, src := range frame.Source {
+			marker := " "
+			if src.Current {
+				marker = ">"
+			}
+			fmt.Fprintf(&str, "\n\t\t%s %4d | %s", marker, src.Number, src.Text)
+		}
+	}
+	return str.String()
+}
+
+// panicPage 开发模式下发生 panic 时返回的页面
+var panicPage = template.Must(template.New("panic").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>panic: {{.Message}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+h1 { color: #c0392b; font-size: 1.4em; }
+pre { background: #f6f6f6; padding: .8em; overflow-x: auto; }
+.frame { margin-bottom: 1em; }
+.func { font-weight: bold; }
+.app .func { color: #c0392b; }
+.file { color: #777; font-family: monospace; }
+.current { background: #fde2e2; }
+</style>
+</head>
+<body>
+<h1>panic: {{.Message}}</h1>
+<h2>Traceback</h2>
+{{range .Stack}}<div class="frame{{if .App}} app{{end}}">
+<div class="func">{{.Function}}</div>
+<div class="file">{{.File}}:{{.Line}}</div>
+{{if .Source}}<pre>{{range .Source}}<span{{if .Current}} class="current"{{end}}>{{printf "%4d" .Number}} | {{.Text}}</span>
+{{end}}</pre>{{end}}
+</div>
+{{end}}
+<h2>Request</h2>
+<pre>{{.Request}}</pre>
+</body>
+</html>
+`))
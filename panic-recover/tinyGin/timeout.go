@@ -0,0 +1,317 @@
+package tinyGin
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+/*
+Timeout 中间件限制请求的处理时间。
+后续的中间件和 handler 在新的协程中运行，c.Req.Context() 在超时后被取消，
+handler 写入的响应先保存在缓冲区中，按时完成时才写给客户端；超时后直接返回 503，handler 之后的写入全部丢弃，
+所以超时响应不会和 handler 的响应混在一起。
+
+	r.Use(Timeout(5 * time.Second))
+	upload := r.Group("/upload")
+	upload.Use(Timeout(time.Minute)) // 覆盖全局的超时时间
+
+嵌套使用时只有最外层的 Timeout 启动协程，内层的 Timeout 修改同一个截止时间，时间都从请求开始时计算。
+因为响应被缓冲，Timeout 之后的 handler 不能使用 SSE、Stream 之类的流式响应，也不能 Hijack 连接。
+*/
+
+// TimeoutOptions Timeout 中间件的配置
+type TimeoutOptions struct {
+	// Response 超时时返回的响应，默认返回 503 Service Unavailable
+	Response HandlerFunc
+}
+
+// Timeout 限制后续处理的时间不超过 d
+func Timeout(d time.Duration, opts ...TimeoutOptions) HandlerFunc {
+	response := defaultTimeoutResponse
+	if len(opts) > 0 && opts[0].Response != nil {
+		response = opts[0].Response
+	}
+	return func(c *Context) {
+		if c.timeout != nil {
+			// 外层已经有 Timeout，只修改截止时间
+			c.timeout.reset(d)
+			c.Next()
+			return
+		}
+
+		buf := &timeoutWriter{header: c.Writer.Header().Clone()}
+		tc := newTimeoutController(c.Req.Context(), d, buf)
+		defer tc.stop()
+		cp := c.copyForTimeout(buf, tc)
+
+		finished := make(chan struct{})
+		panicked := make(chan *handlerPanic, 1)
+		go func() {
+			defer func() {
+				if err := recover(); err != nil {
+					// 重新抛出之后调用栈就只剩下 Timeout 本身了，在 handler 协程中先记录下来
+					panicked <- &handlerPanic{value: err, pcs: callerPCs(1), stack: debug.Stack()}
+					return
+				}
+				close(finished)
+			}()
+			cp.Next()
+		}()
+
+		select {
+		case p := <-panicked:
+			// 交给外层的 Recovery 处理，http.ErrAbortHandler 要原样抛出，net/http 才不会记录日志
+			if p.value == http.ErrAbortHandler {
+				panic(p.value)
+			}
+			panic(p)
+		case <-finished:
+			if tc.finish() {
+				buf.flush(c, cp)
+				return
+			}
+			// handler 完成的同时到达了截止时间，之后的写入已经被丢弃，缓存的响应不完整，按超时处理
+			c.Abort()
+			response(c)
+		case <-tc.expired:
+			// handler 协程还在运行，之后发生 panic 时不能再交给外层处理，只记录日志
+			go func() {
+				select {
+				case p := <-panicked:
+					log.Printf("[Timeout] panic after timeout: %v\n%s", p.value, p.stack)
+				case <-finished:
+				}
+			}()
+			c.Abort()
+			response(c)
+		}
+	}
+}
+
+// defaultTimeoutResponse 返回 503
+func defaultTimeoutResponse(c *Context) {
+	c.Fail(http.StatusServiceUnavailable, "Service Unavailable")
+}
+
+// copyForTimeout 复制一个在新协程中运行后续 handler 的 Context，响应写入 w
+// Req、Keys 和 Errors 单独复制一份，超时之后两个协程不会访问同一份数据，例如 Forward 会修改 Req.URL
+func (c *Context) copyForTimeout(w http.ResponseWriter, tc *timeoutController) *Context {
+	cp := &Context{
+		Req:        c.Req.Clone(tc),
+		Path:       c.Path,
+		Method:     c.Method,
+		Params:     c.Params,
+		fullPath:   c.fullPath,
+		handlers:   c.handlers,
+		index:      c.index,
+		forwards:   c.forwards,
+		groups:     c.groups,
+		Errors:     append(errorMsgs(nil), c.Errors...),
+		queryCache: c.queryCache,
+		formCache:  c.formCache,
+		rawData:    c.rawData,
+		Keys:       c.copyKeys(),
+		engine:     c.engine,
+		timeout:    tc,
+	}
+	cp.writermem.reset(w)
+	cp.Writer = &cp.writermem
+	return cp
+}
+
+// handlerPanic 在 handler 协程中捕获的 panic 以及当时的调用栈
+// Recovery 会取出原始的值和调用栈；没有 Recovery 时由 net/http 记录，Error 中包含 handler 协程的调用栈
+type handlerPanic struct {
+	value interface{}
+	pcs   []uintptr
+	stack []byte
+}
+
+func (p *handlerPanic) Error() string {
+	return fmt.Sprintf("%v\n\nhandler goroutine stack:\n%s", p.value, p.stack)
+}
+
+// timeoutWriter 缓存 handler 写入的响应
+type timeoutWriter struct {
+	header http.Header
+	body   bytes.Buffer
+
+	mu       sync.Mutex
+	timedOut bool
+}
+
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	return w.body.Write(data)
+}
+
+// WriteHeader 状态码由外面包装的 responseWriter 记录，这里不需要处理
+func (w *timeoutWriter) WriteHeader(code int) {}
+
+// timeout 标记为超时，之后的写入全部丢弃
+func (w *timeoutWriter) timeout() {
+	w.mu.Lock()
+	w.timedOut = true
+	w.mu.Unlock()
+}
+
+// flush handler 按时完成后，把缓存的响应和 cp 中的数据写回 c
+func (w *timeoutWriter) flush(c *Context, cp *Context) {
+	dst := c.Writer.Header()
+	for k := range dst {
+		if _, ok := w.header[k]; !ok {
+			delete(dst, k)
+		}
+	}
+	for k, v := range w.header {
+		dst[k] = v
+	}
+	c.Writer.WriteHeader(cp.Writer.Status())
+	c.StatusCode = c.Writer.Status()
+	if cp.Writer.Written() {
+		c.Writer.WriteHeaderNow()
+		c.Writer.Write(w.body.Bytes())
+	}
+
+	c.mu.Lock()
+	c.Keys = cp.Keys
+	c.mu.Unlock()
+	c.Errors = cp.Errors
+	c.index = cp.index
+	// handler 中调用了 Forward 时，外层的日志等中间件看到的应该是转发之后的路由
+	c.Req.URL = cp.Req.URL
+	c.Path = cp.Path
+	c.Params = cp.Params
+	c.fullPath = cp.fullPath
+}
+
+// timeoutController 实现 context.Context，截止时间可以被内层的 Timeout 修改
+// 父 Context 被取消(例如客户端断开连接)时同样会被取消
+type timeoutController struct {
+	context.Context
+	start     time.Time
+	expired   chan struct{} // 到达截止时间时关闭
+	done      chan struct{} // 到达截止时间或者父 Context 被取消时关闭
+	stopAfter func() bool
+	writer    *timeoutWriter
+
+	mu        sync.Mutex
+	deadline  time.Time
+	timer     *time.Timer
+	timedOut  bool
+	completed bool // handler 已经按时完成，不会再超时
+	err       error
+}
+
+func newTimeoutController(parent context.Context, d time.Duration, w *timeoutWriter) *timeoutController {
+	start := time.Now()
+	tc := &timeoutController{
+		Context:  parent,
+		start:    start,
+		expired:  make(chan struct{}),
+		done:     make(chan struct{}),
+		deadline: start.Add(d),
+		writer:   w,
+	}
+	tc.mu.Lock()
+	tc.timer = time.AfterFunc(d, tc.expire)
+	tc.mu.Unlock()
+	tc.stopAfter = context.AfterFunc(parent, func() {
+		tc.cancel(parent.Err())
+	})
+	return tc
+}
+
+// reset 把截止时间修改为请求开始后的 d
+func (tc *timeoutController) reset(d time.Duration) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if tc.timedOut {
+		return
+	}
+	tc.deadline = tc.start.Add(d)
+	tc.timer.Reset(time.Until(tc.deadline))
+}
+
+// expire 定时器触发时调用，截止时间被推迟时重新等待
+func (tc *timeoutController) expire() {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if wait := time.Until(tc.deadline); wait > 0 {
+		tc.timer.Reset(wait)
+		return
+	}
+	if tc.timedOut || tc.completed {
+		return
+	}
+	tc.timedOut = true
+	// 先丢弃之后的写入，再通知 handler，handler 看到 Done 之后的写入一定不会进入响应
+	tc.writer.timeout()
+	close(tc.expired)
+	if tc.err == nil {
+		tc.err = context.DeadlineExceeded
+		close(tc.done)
+	}
+}
+
+// finish handler 完成时调用，返回 false 表示已经超时，handler 的写入可能被丢弃了一部分
+func (tc *timeoutController) finish() bool {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if tc.timedOut {
+		return false
+	}
+	tc.completed = true
+	tc.timer.Stop()
+	return true
+}
+
+// cancel 取消 Context，只有第一次调用生效
+func (tc *timeoutController) cancel(err error) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if tc.err != nil {
+		return
+	}
+	tc.err = err
+	close(tc.done)
+}
+
+// stop 请求处理结束后停止定时器并取消 Context，handler 启动的协程不会一直持有它
+func (tc *timeoutController) stop() {
+	tc.mu.Lock()
+	tc.timer.Stop()
+	tc.mu.Unlock()
+	tc.stopAfter()
+	tc.cancel(context.Canceled)
+}
+
+func (tc *timeoutController) Deadline() (time.Time, bool) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	return tc.deadline, true
+}
+
+func (tc *timeoutController) Done() <-chan struct{} {
+	return tc.done
+}
+
+func (tc *timeoutController) Err() error {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	return tc.err
+}
@@ -0,0 +1,147 @@
+package tinyGin
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTimeout(t *testing.T) {
+	late := make(chan error, 1)
+	r := New()
+	r.Use(Timeout(50 * time.Millisecond))
+	r.GET("/fast", func(c *Context) {
+		c.Set("handled", true)
+		c.SetHeader("X-Handler", "fast")
+		c.String(http.StatusCreated, "done")
+	})
+	r.GET("/slow", func(c *Context) {
+		<-c.Req.Context().Done()
+		if c.Req.Context().Err() != context.DeadlineExceeded {
+			late <- c.Req.Context().Err()
+			return
+		}
+		c.SetHeader("X-Handler", "slow")
+		_, err := c.Writer.WriteString("too late")
+		late <- err
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/fast", nil))
+	if w.Code != http.StatusCreated || w.Body.String() != "done" || w.Header().Get("X-Handler") != "fast" {
+		t.Fatalf("unexpected response %d %q %v", w.Code, w.Body.String(), w.Header())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/slow", nil))
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("X-Handler") != "" {
+		t.Fatalf("expected 503, got %d %v", w.Code, w.Header())
+	}
+	if err := <-late; err != http.ErrHandlerTimeout {
+		t.Fatalf("late write should be discarded, got %v", err)
+	}
+	if bytes.Contains(w.Body.Bytes(), []byte("too late")) {
+		t.Fatalf("late write leaked into response %q", w.Body.String())
+	}
+}
+
+func TestTimeoutForward(t *testing.T) {
+	var fullPath, path string
+	r := New()
+	r.Use(func(c *Context) {
+		c.Next()
+		fullPath, path = c.FullPath(), c.Req.URL.Path
+	})
+	r.Use(Timeout(time.Second))
+	r.GET("/old/:id", func(c *Context) {
+		c.Forward("/new/" + c.Param("id"))
+	})
+	r.GET("/new/:id", func(c *Context) {
+		c.String(http.StatusOK, c.Param("id"))
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/old/7", nil))
+	if w.Body.String() != "7" || fullPath != "/new/:id" || path != "/new/7" {
+		t.Fatalf("forward inside Timeout should be visible outside: %q %s %s", w.Body.String(), fullPath, path)
+	}
+}
+
+func TestTimeoutGroupOverride(t *testing.T) {
+	r := New()
+	r.Use(Timeout(20 * time.Millisecond))
+	slow := r.Group("/upload")
+	slow.Use(Timeout(time.Second))
+	handler := func(c *Context) {
+		select {
+		case <-time.After(60 * time.Millisecond):
+			c.String(http.StatusOK, "ok")
+		case <-c.Req.Context().Done():
+		}
+	}
+	r.GET("/api", handler)
+	slow.GET("/file", handler)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("engine default should apply, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/upload/file", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "ok" {
+		t.Fatalf("group override should apply, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestTimeoutPanic(t *testing.T) {
+	var buf bytes.Buffer
+	r := New()
+	r.Use(RecoveryWithWriter(&buf), Timeout(time.Second, TimeoutOptions{
+		Response: func(c *Context) { c.String(http.StatusGatewayTimeout, "timeout") },
+	}))
+	r.GET("/panic", func(c *Context) {
+		panic("boom")
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/panic", nil))
+	if w.Code != http.StatusInternalServerError || !bytes.Contains(buf.Bytes(), []byte("boom")) {
+		t.Fatalf("panic should reach Recovery, got %d", w.Code)
+	}
+	// 日志中是 handler 协程的调用栈，而不是重新抛出的位置
+	if !bytes.Contains(buf.Bytes(), []byte("tinyGin.TestTimeoutPanic.func2")) {
+		t.Fatalf("handler stack should be kept: %s", buf.String())
+	}
+}
+
+func TestTimeoutFinishAfterExpire(t *testing.T) {
+	buf := &timeoutWriter{header: http.Header{}}
+	tc := newTimeoutController(context.Background(), time.Hour, buf)
+	defer tc.stop()
+	tc.mu.Lock()
+	tc.deadline = time.Now()
+	tc.mu.Unlock()
+	// handler 完成的同时到达截止时间，写入已经被丢弃，不能再当作按时完成
+	tc.expire()
+	if tc.finish() {
+		t.Fatal("finish should report the timeout")
+	}
+
+	tc = newTimeoutController(context.Background(), time.Hour, &timeoutWriter{header: http.Header{}})
+	defer tc.stop()
+	if !tc.finish() {
+		t.Fatal("finish before the deadline should succeed")
+	}
+	tc.mu.Lock()
+	tc.deadline = time.Now()
+	tc.mu.Unlock()
+	// 已经完成之后定时器触发也不会再超时
+	tc.expire()
+	select {
+	case <-tc.expired:
+		t.Fatal("completed handler should not expire")
+	default:
+	}
+}
@@ -3,6 +3,7 @@ package tinyGin
 import (
 	"html/template"
 	"log"
+	"net"
 	"net/http"
 	"path"
 	"strings"
@@ -40,12 +41,35 @@ type Engine struct {
 	groups        []*RouterGroup     // store all groups  要有一个groups存放所有的group信息
 	htmlTemplates *template.Template // for html render	将所有的模板加载进内存
 	funcMap       template.FuncMap   // for html render	是所有的自定义模板渲染函数
+	// SecureJson 返回数组时添加的前缀
+	secureJsonPrefix string
+	// 签名和加密 Cookie 使用的密钥，由 SetCookieSecrets 设置
+	cookieKeys []cookieKey
+	// 可信代理的地址段，由 SetTrustedProxies 设置
+	trustedCIDRs []*net.IPNet
+	// 日志是否带颜色，由 DisableConsoleColor/ForceConsoleColor 设置
+	consoleColor consoleColorMode
+
+	// HandleMethodNotAllowed 为 true 时，路径存在但请求方法不匹配的请求返回 405 而不是 404
+	HandleMethodNotAllowed bool
+	// RemoteIPHeaders 请求来自可信代理时，ClientIP 按顺序从这些请求头中解析客户端地址
+	RemoteIPHeaders []string
+	// MaxMultipartMemory 解析 multipart 表单时最多保存在内存中的字节数，超出的部分写入临时文件
+	MaxMultipartMemory int64
+	// MaxRawDataSize GetRawData 和 ShouldBindBodyWith 最多读取的请求体字节数
+	MaxRawDataSize int64
+	// ProblemDetails 为 true 时，框架生成的错误响应(Fail、Recovery、404/405、参数校验)使用 RFC 7807 的 problem+json 格式
+	ProblemDetails bool
 }
 
 // New is the constructor of tinyGin.Engine
 func New() *Engine {
 	e := &Engine{
-		router: newRouter(),
+		router:             newRouter(),
+		secureJsonPrefix:   "while(1);",
+		MaxMultipartMemory: defaultMultipartMemory,
+		RemoteIPHeaders:    defaultRemoteIPHeaders,
+		MaxRawDataSize:     defaultMaxRawDataSize,
 	}
 	e.RouterGroup = &RouterGroup{
 		engine: e,
@@ -129,18 +153,28 @@ func (group *RouterGroup) Static(relativePath, root string) {
 
 // Engine实现的 ServeHTTP 方法的作用：解析请求的路径，查找路由映射表，如果查到，就执行注册的处理方法。如果查不到，就返回 404 NOT FOUND
 func (e *Engine) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	c := newContext(w, req)
+	// 在 Context 中添加了成员变量 engine *Engine，这样就能够通过 Context 访问 Engine 中的 HTML 模板。实例化 Context 时，还需要给 c.engine 赋值
+	c.engine = e
+	e.handleHTTPRequest(c)
+	// handler 只设置了状态码而没有写入响应体时，在这里把 Header 发送出去
+	c.Writer.WriteHeaderNow()
+	c.StatusCode = c.Writer.Status()
+}
+
+// handleHTTPRequest 根据 c.Path 找出需要执行的中间件和处理函数并依次执行，c.Forward 也会调用这里重新分发请求
+func (e *Engine) handleHTTPRequest(c *Context) {
 	middlewares := []HandlerFunc{}
 	// 查出本次请求所有需要调用的中间件
 	for _, group := range e.groups {
-		if strings.HasPrefix(req.URL.Path, group.prefix) {
+		// Forward 之前已经执行过中间件的分组不再重复执行
+		if strings.HasPrefix(c.Path, group.prefix) && !c.groupHandled(group) {
 			// 保持下来放到context中
 			middlewares = append(middlewares, group.middlewares...)
+			c.groups = append(c.groups, group)
 		}
 	}
-	c := newContext(w, req)
 	c.handlers = middlewares
-	// 在 Context 中添加了成员变量 engine *Engine，这样就能够通过 Context 访问 Engine 中的 HTML 模板。实例化 Context 时，还需要给 c.engine 赋值
-	c.engine = e
 	// 查出本次请求对应的处理函数，然后再依次开始请求
 	e.router.handle(c)
 }
@@ -161,3 +195,9 @@ func (e *Engine) SetFuncMap(funcMap template.FuncMap) {
 func (e *Engine) LoadHTMLGlob(pattern string) {
 	e.htmlTemplates = template.Must(template.New("").Funcs(e.funcMap).ParseGlob(pattern))
 }
+
+// SecureJsonPrefix 设置 Context.SecureJson 使用的前缀
+func (e *Engine) SecureJsonPrefix(prefix string) *Engine {
+	e.secureJsonPrefix = prefix
+	return e
+}
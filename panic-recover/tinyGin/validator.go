@@ -0,0 +1,213 @@
+package tinyGin
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+/*
+参数校验：解析完请求数据之后，根据结构体字段上的 binding tag 对字段进行校验，例如
+	Name  string `json:"name" binding:"required,min=3,max=64"`
+	Email string `json:"email" binding:"omitempty,email"`
+	Role  string `json:"role" binding:"oneof=admin user"`
+支持的规则：
+	required   字段不能是零值
+	omitempty  字段是零值时跳过后面的规则
+	min/max    字符串按字符数、切片和 map 按长度、数字按数值比较
+	len        长度(或数值)必须相等
+	email      必须是合法的邮箱地址
+	oneof      必须是空格分隔的若干值之一
+嵌套的结构体字段会递归校验，字段名用 . 连接，例如 address.city
+*/
+
+// FieldError 描述一个字段校验失败的原因
+type FieldError struct {
+	Field   string      `json:"field"`           // 字段名，优先使用 json tag，其次 form tag，最后是结构体字段名
+	Tag     string      `json:"tag"`             // 失败的规则，例如 required、min
+	Param   string      `json:"param,omitempty"` // 规则的参数，例如 min=3 中的 3
+	Value   interface{} `json:"-"`               // 字段的实际值
+	Message string      `json:"message"`         // 可读的错误信息
+}
+
+func (fe *FieldError) Error() string {
+	return fe.Message
+}
+
+// ValidationErrors 所有校验失败的字段，ShouldBind 校验失败时返回该类型
+type ValidationErrors []*FieldError
+
+func (ve ValidationErrors) Error() string {
+	msgs := make([]string, len(ve))
+	for i, fe := range ve {
+		msgs[i] = fe.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// validate 校验 obj 中所有带 binding tag 的字段，obj 可以是结构体或结构体指针
+func validate(obj interface{}) error {
+	rv := reflect.ValueOf(obj)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	var errs ValidationErrors
+	validateStruct(rv, "", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func validateStruct(rv reflect.Value, prefix string, errs *ValidationErrors) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		fv := rv.Field(i)
+		name := prefix + fieldName(field)
+		// 未导出的匿名字段无法读取，只校验其中导出的字段
+		if tag := field.Tag.Get("binding"); tag != "" && tag != "-" && fv.CanInterface() {
+			validateField(fv, name, tag, errs)
+		}
+		// 递归校验嵌套结构体，匿名字段沿用外层的前缀
+		inner := fv
+		for inner.Kind() == reflect.Ptr && !inner.IsNil() {
+			inner = inner.Elem()
+		}
+		if inner.Kind() == reflect.Struct && inner.Type() != timeType {
+			if field.Anonymous {
+				validateStruct(inner, prefix, errs)
+			} else {
+				validateStruct(inner, name+".", errs)
+			}
+		}
+	}
+}
+
+// fieldName 返回错误信息中使用的字段名
+func fieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "form"} {
+		if tag := field.Tag.Get(key); tag != "" && tag != "-" {
+			if name := strings.Split(tag, ",")[0]; name != "" {
+				return name
+			}
+		}
+	}
+	return field.Name
+}
+
+func validateField(fv reflect.Value, name, tag string, errs *ValidationErrors) {
+	rules := strings.Split(tag, ",")
+	for _, rule := range rules {
+		key, param := rule, ""
+		if i := strings.IndexByte(rule, '='); i >= 0 {
+			key, param = rule[:i], rule[i+1:]
+		}
+		switch key {
+		case "omitempty":
+			if fv.IsZero() {
+				return
+			}
+			continue
+		case "required":
+			if fv.IsZero() {
+				*errs = append(*errs, newFieldError(fv, name, key, param, "%s is required", name))
+				// 必填字段缺失时，其余规则就没有意义了
+				return
+			}
+			continue
+		}
+		if fe := checkRule(fv, name, key, param); fe != nil {
+			*errs = append(*errs, fe)
+		}
+	}
+}
+
+func newFieldError(fv reflect.Value, name, tag, param, format string, args ...interface{}) *FieldError {
+	var value interface{}
+	if fv.IsValid() && fv.CanInterface() {
+		value = fv.Interface()
+	}
+	return &FieldError{
+		Field:   name,
+		Tag:     tag,
+		Param:   param,
+		Value:   value,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+var emailRegexp = regexp.MustCompile(`^[a-zA-Z0-9.!#$%&'*+/=?^_{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)+$`)
+
+func checkRule(fv reflect.Value, name, key, param string) *FieldError {
+	// 指针字段校验其指向的值，nil 指针只有 required 会报错
+	for fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			return nil
+		}
+		fv = fv.Elem()
+	}
+	switch key {
+	case "min", "max", "len":
+		limit, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			panic(fmt.Sprintf("tinyGin: invalid binding param %s=%s on %s", key, param, name))
+		}
+		size, unit, ok := measure(fv)
+		if !ok {
+			return nil
+		}
+		switch {
+		case key == "min" && size < limit:
+			return newFieldError(fv, name, key, param, "%s must be at least %s%s", name, param, unit)
+		case key == "max" && size > limit:
+			return newFieldError(fv, name, key, param, "%s must be at most %s%s", name, param, unit)
+		case key == "len" && size != limit:
+			return newFieldError(fv, name, key, param, "%s must be exactly %s%s", name, param, unit)
+		}
+	case "email":
+		if fv.Kind() == reflect.String && !emailRegexp.MatchString(fv.String()) {
+			return newFieldError(fv, name, key, param, "%s must be a valid email address", name)
+		}
+	case "oneof":
+		value := fmt.Sprint(fv.Interface())
+		for _, option := range strings.Fields(param) {
+			if value == option {
+				return nil
+			}
+		}
+		return newFieldError(fv, name, key, param, "%s must be one of [%s]", name, param)
+	default:
+		panic(fmt.Sprintf("tinyGin: unknown binding rule %q on %s", key, name))
+	}
+	return nil
+}
+
+// measure 返回 min/max/len 比较时使用的值：字符串是字符数，切片和 map 是长度，数字是数值本身
+func measure(fv reflect.Value) (float64, string, bool) {
+	switch fv.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(fv.String())), " characters", true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(fv.Len()), " items", true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(fv.Int()), "", true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(fv.Uint()), "", true
+	case reflect.Float32, reflect.Float64:
+		return fv.Float(), "", true
+	}
+	return 0, "", false
+}